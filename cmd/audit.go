@@ -0,0 +1,193 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// sensitiveFlags are the substrings of flag names, and of keys, whose values must not end up in the audit log
+var sensitiveFlags = []string{"password", "token", "secret", "key"}
+
+// auditRecord is a single entry of the audit log
+type auditRecord struct {
+	Time    time.Time         `json:"time"`
+	User    string            `json:"user"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Flags   map[string]string `json:"flags"`
+	Result  string            `json:"result"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// auditor appends the outcome of a mutating command to the audit log.
+//
+// It is also a logrus hook, since failing commands usually exit through logger.Fatal.
+type auditor struct {
+	path   string
+	record auditRecord
+	once   sync.Once
+}
+
+var _ logger.Hook = &auditor{}
+
+// newAuditor creates an auditor for c, capturing its positional args and the values of its flags.
+// Sensitive values, in flags and args, are redacted (see redact).
+//
+// It must be called after the flags are bound to ENV and config file (see initFlags),
+// to record the values the command runs with wherever they come from.
+func newAuditor(path string, c *cobra.Command, args []string) *auditor {
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	flags := map[string]string{}
+	// flags set from ENV and config file are only marked as changed (see setConfigFlag), Visit would skip them
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			values := []string{}
+			for _, value := range sv.GetSlice() {
+				values = append(values, redact(f.Name, value))
+			}
+			flags[f.Name] = "[" + strings.Join(values, ",") + "]"
+			return
+		}
+		flags[f.Name] = redact(f.Name, f.Value.String())
+	})
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		redacted = append(redacted, redact("", arg))
+	}
+	return &auditor{
+		path: path,
+		record: auditRecord{
+			User:    username,
+			Command: c.CommandPath(),
+			Args:    redacted,
+			Flags:   flags,
+		},
+	}
+}
+
+// redact hides value when name is sensitive, otherwise the secrets it embeds:
+// the password of a URL, or the value of a key=value pair whose key is sensitive.
+func redact(name, value string) string {
+	if isSensitive(name) {
+		return "<redacted>"
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.User(u.User.Username())
+			// userinfo is escaped, so its first @ ends it
+			return strings.Replace(u.String(), "@", ":<redacted>@", 1)
+		}
+	}
+	if kv := strings.SplitN(value, "=", 2); len(kv) == 2 && isSensitive(kv[0]) {
+		return kv[0] + "=<redacted>"
+	}
+	return value
+}
+
+// isSensitive returns true if name contains any of sensitiveFlags
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveFlags {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap makes c record its outcome to the audit log
+func (a *auditor) wrap(c *cobra.Command) {
+	logger.AddHook(a)
+	if run := c.RunE; run != nil {
+		c.RunE = func(c *cobra.Command, args []string) error {
+			err := run(c, args)
+			a.write(err)
+			return err
+		}
+	} else if run := c.Run; run != nil {
+		c.Run = func(c *cobra.Command, args []string) {
+			run(c, args)
+			a.write(nil)
+		}
+	}
+}
+
+// Levels returns the levels the auditor hooks into
+func (a *auditor) Levels() []logger.Level {
+	return []logger.Level{logger.PanicLevel, logger.FatalLevel}
+}
+
+// Fire records the command failure
+func (a *auditor) Fire(entry *logger.Entry) error {
+	msg := entry.Message
+	if err, ok := entry.Data[logger.ErrorKey]; ok {
+		msg = strings.TrimPrefix(fmt.Sprintf("%s: %v", msg, err), ": ")
+	}
+	a.write(errors.New(msg))
+	return nil
+}
+
+// write appends the record, only once, to the audit log
+func (a *auditor) write(err error) {
+	a.once.Do(func() {
+		r := a.record
+		r.Time = time.Now().UTC()
+		r.Result = "success"
+		if err != nil {
+			r.Result = "failure"
+			r.Error = err.Error()
+		}
+		if err := appendAuditRecord(a.path, r); err != nil {
+			logger.WithError(err).WithField("file", a.path).Error("error writing audit log")
+		}
+	})
+}
+
+func appendAuditRecord(path string, r auditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -9,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/acarl005/stripansi"
+	logger "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"gotest.tools/assert"
 )
 
@@ -53,10 +56,44 @@ var tests = []testCase{
 			out: "testdata/help.txt",
 		},
 	},
+	{
+		args: []string{"search", "registry", "--explain-config", "registryurl"},
+		expect: expect{
+			out: "testdata/explainconfig-default.txt",
+		},
+	},
+	{
+		args: []string{"search", "registry", "--explain-config", "registryurl", "-c", "testdata/config.yaml"},
+		expect: expect{
+			out: "testdata/explainconfig-file.txt",
+		},
+	},
+	{
+		env:  map[string]string{"FALCOCTL_REGISTRYURL": "https://env.example.com/registry.yaml"},
+		args: []string{"search", "registry", "--explain-config", "registryurl", "-c", "testdata/config.yaml"},
+		expect: expect{
+			out: "testdata/explainconfig-env.txt",
+		},
+	},
+	{
+		env:  map[string]string{"FALCOCTL_REGISTRYURL": "https://env.example.com/registry.yaml"},
+		args: []string{"search", "registry", "--explain-config", "registryurl", "-c", "testdata/config.yaml", "--no-env"},
+		expect: expect{
+			out: "testdata/explainconfig-noenv.txt",
+		},
+	},
+	{
+		env:  map[string]string{"FALCOCTL_REGISTRYURL": "https://env.example.com/registry.yaml"},
+		args: []string{"search", "registry", "--explain-config", "registryurl", "-c", "testdata/config.yaml", "-r", "https://flag.example.com/registry.yaml"},
+		expect: expect{
+			out: "testdata/explainconfig-flag.txt",
+		},
+	},
 }
 
 func run(t *testing.T, test testCase) {
 	// Setup
+	viper.Reset()
 	c := New(nil)
 	o := bytes.NewBufferString("")
 	c.SetOut(o)
@@ -94,6 +131,32 @@ func run(t *testing.T, test testCase) {
 	}
 }
 
+// execute runs the CLI with args, returning its output
+func execute(t *testing.T, args ...string) (string, error) {
+	viper.Reset()
+	c := New(nil)
+	o := bytes.NewBufferString("")
+	c.SetOut(o)
+	c.SetErr(o)
+	c.SetArgs(args)
+	err := c.Execute()
+	return o.String(), err
+}
+
+func TestLogOutput(t *testing.T) {
+	defer logger.SetOutput(os.Stderr)
+
+	out, err := execute(t, "--loglevel", "debug", "--log-output", "stdout")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out, "logs are written to stdout together with the command results"))
+	assert.Assert(t, strings.Contains(out, "running with args"))
+
+	out, err = execute(t, "--loglevel", "debug", "--log-output", "stderr")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(out, "running with args"))
+	assert.Equal(t, logger.StandardLogger().Out, io.Writer(os.Stderr))
+}
+
 func TestCLI(t *testing.T) {
 	for _, test := range tests {
 		descr := test.descr
@@ -0,0 +1,38 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCmd creates the `config` command
+func NewConfigCmd(options CommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "config",
+		DisableFlagsInUseLine: true,
+		Short:                 "Inspect the falcoctl configuration",
+		Long:                  `Inspect the falcoctl configuration`,
+		// the config commands work on the configuration, so they are not configured by it
+		Annotations: map[string]string{annotationUnbound: "true"},
+	}
+
+	cmd.AddCommand(NewConfigDiffCmd(NewConfigDiffOptions()))
+	cmd.AddCommand(NewConfigValidateCmd(nil))
+
+	return cmd
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var _ CommandOptions = &ConfigDiffOptions{}
+
+// ConfigDiffOptions represents the `config diff` command options
+type ConfigDiffOptions struct {
+	output string
+}
+
+// AddFlags adds flag to c
+func (o *ConfigDiffOptions) AddFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: text, json")
+}
+
+// Validate validates the `config diff` command options
+func (o *ConfigDiffOptions) Validate(c *cobra.Command, args []string) error {
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("unknown output format \"%s\", must be one of: text, json", o.output)
+	}
+	return nil
+}
+
+// NewConfigDiffOptions instantiates the `config diff` command options
+func NewConfigDiffOptions() *ConfigDiffOptions {
+	return &ConfigDiffOptions{
+		output: "text",
+	}
+}
+
+// configChange is a configuration value that differs between a config file and the effective configuration
+type configChange struct {
+	File      string `json:"file"`
+	Effective string `json:"effective"`
+}
+
+// configDiff represents the differences of the effective configuration from a config file
+type configDiff struct {
+	Added   map[string]string       `json:"added"`
+	Changed map[string]configChange `json:"changed"`
+	Removed map[string]string       `json:"removed"`
+}
+
+// NewConfigDiffCmd creates the `config diff` command
+func NewConfigDiffCmd(options CommandOptions) *cobra.Command {
+	o := options.(*ConfigDiffOptions)
+
+	cmd := &cobra.Command{
+		Use:                   "diff [file]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show how the effective configuration differs from a config file",
+		Long: `Show how the effective configuration (ENV, config file and defaults) differs from a config file.
+
+When no file is given, the config file in use is compared.
+Keys set by the effective configuration only are reported as added,
+keys whose value differs as changed, and keys unknown to falcoctl as removed.`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: o.Validate,
+		RunE: func(c *cobra.Command, args []string) error {
+			file := viper.ConfigFileUsed()
+			if len(args) > 0 {
+				file = args[0]
+			}
+			if file == "" {
+				return fmt.Errorf("no config file in use, please provide one")
+			}
+			v := viper.New()
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err != nil {
+				return fmt.Errorf("could not read config file \"%s\": %s", file, err.Error())
+			}
+
+			noEnv, _ := c.Flags().GetBool("no-env")
+			diff, err := diffConfig(c.Root(), v, !noEnv)
+			if err != nil {
+				return err
+			}
+			if o.output == "json" {
+				return json.NewEncoder(c.OutOrStdout()).Encode(diff)
+			}
+			printConfigDiff(c.OutOrStdout(), diff)
+			return nil
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+// configFlags returns the flags bound to ENV and config file of all the commands under root, by name
+func configFlags(root *cobra.Command) map[string]*pflag.FlagSet {
+	flags := map[string]*pflag.FlagSet{}
+	for name, commands := range configCommands(root) {
+		flags[name] = commands[0].Flags()
+	}
+	return flags
+}
+
+// diffConfig compares the effective configuration of the commands under root against the config file loaded in v,
+// considering the ENV variables only when env is true
+func diffConfig(root *cobra.Command, v *viper.Viper, env bool) (*configDiff, error) {
+	diff := &configDiff{
+		Added:   map[string]string{},
+		Changed: map[string]configChange{},
+		Removed: map[string]string{},
+	}
+	flags := configFlags(root)
+	for key, fs := range flags {
+		sources, err := configSources(fs, key, true, env)
+		if err != nil {
+			return nil, err
+		}
+		var effective configSource
+		for _, effective = range sources {
+			if effective.set {
+				break
+			}
+		}
+		if v.IsSet(key) {
+			if value := configValue(v, fs.Lookup(key)); value != effective.value {
+				diff.Changed[key] = configChange{File: value, Effective: effective.value}
+			}
+		} else if effective.name != "default" {
+			diff.Added[key] = effective.value
+		}
+	}
+	for _, key := range v.AllKeys() {
+		// the keys of mappings (e.g. registry-regions.eu) belong to their flag
+		if _, ok := flags[strings.SplitN(key, ".", 2)[0]]; !ok {
+			diff.Removed[key] = v.GetString(key)
+		}
+	}
+	return diff, nil
+}
+
+// printConfigDiff prints one line per key, sorted by key, marking added (+), changed (~) and removed (-) ones
+func printConfigDiff(out io.Writer, diff *configDiff) {
+	lines := map[string]string{}
+	for k, v := range diff.Added {
+		lines[k] = fmt.Sprintf("+ %s: %s", k, v)
+	}
+	for k, c := range diff.Changed {
+		lines[k] = fmt.Sprintf("~ %s: %s -> %s", k, c.File, c.Effective)
+	}
+	for k, v := range diff.Removed {
+		lines[k] = fmt.Sprintf("- %s: %s", k, v)
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(out, "no differences")
+		return
+	}
+	keys := make([]string, 0, len(lines))
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintln(out, lines[k])
+	}
+}
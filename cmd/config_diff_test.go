@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"gotest.tools/assert"
+)
+
+func TestConfigDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(file, []byte("registryurl: https://file.example.com/registry.yaml\nall: true\nunknown: value\n"), 0600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+
+	for k, v := range map[string]string{
+		"FALCOCTL_REGISTRYURL":  "https://env.example.com/registry.yaml",
+		"FALCOCTL_AGGREGATE_BY": "license",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	// the given file is not the active one, so only ENV and defaults make up the effective config
+	out, err := execute(t, "config", "diff", file)
+	assert.NilError(t, err)
+	assert.Equal(t, out, `+ aggregate-by: license
+~ all: true -> false
+~ registryurl: https://file.example.com/registry.yaml -> https://env.example.com/registry.yaml
+- unknown: value
+`)
+
+	// the active config file only differs by ENV overrides and its unknown keys
+	out, err = execute(t, "-c", file, "config", "diff", "-o", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `{"added":{"aggregate-by":"license"},"changed":{"registryurl":{"file":"https://file.example.com/registry.yaml","effective":"https://env.example.com/registry.yaml"}},"removed":{"unknown":"value"}}
+`)
+}
+
+func TestConfigDiffNoFile(t *testing.T) {
+	home, err := ioutil.TempDir("", "falcoctl-home")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	homedir.Reset()
+	defer homedir.Reset()
+
+	_, err = execute(t, "config", "diff")
+	assert.Error(t, err, "no config file in use, please provide one")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configSource is one of the places a configuration value can come from
+type configSource struct {
+	name  string
+	value string
+	set   bool
+}
+
+// configSources returns the sources of key, in order of precedence.
+// ENV and config file are only considered when the key is bound to them, ENV only when env is true.
+//
+// It must be called before the flags are bound to ENV and config file (see initFlags),
+// otherwise values coming from those would look like command line ones.
+func configSources(flags *pflag.FlagSet, key string, bound, env bool) ([]configSource, error) {
+	f := flags.Lookup(key)
+	if f == nil {
+		return nil, fmt.Errorf("unknown configuration key \"%s\" for this command", key)
+	}
+
+	sources := []configSource{{name: "command line"}}
+	if f.Changed {
+		sources[0].value, sources[0].set = f.Value.String(), true
+	}
+
+	if bound {
+		if env {
+			key := configEnvKey(key)
+			s := configSource{name: "ENV " + key}
+			s.value, s.set = os.LookupEnv(key)
+			sources = append(sources, s)
+		}
+
+		file := viper.ConfigFileUsed()
+		s := configSource{name: "config file"}
+		if file != "" {
+			s.name += " " + file
+			v := viper.New()
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err == nil && v.IsSet(key) {
+				s.value, s.set = configValue(v, f), true
+			}
+		}
+		sources = append(sources, s)
+	}
+
+	sources = append(sources, configSource{name: "default", value: f.DefValue, set: true})
+	return sources, nil
+}
+
+// configValue returns the value v holds for the key of f, formatted the way f formats it
+func configValue(v *viper.Viper, f *pflag.Flag) string {
+	values, _, err := configFlagValues(v, f)
+	if err != nil {
+		return fmt.Sprint(v.Get(f.Name))
+	}
+	if _, ok := f.Value.(pflag.SliceValue); ok || f.Value.Type() == "stringToString" {
+		return "[" + strings.Join(values, ",") + "]"
+	}
+	return values[0]
+}
+
+// configEnvKey returns the ENV variable bound to key (see initEnv)
+func configEnvKey(key string) string {
+	return "FALCOCTL_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// explainConfig prints the resolved value for key and where it comes from, marking the winning source
+func explainConfig(out io.Writer, flags *pflag.FlagSet, key string, sources []configSource) {
+	fmt.Fprintf(out, "%s: %s\n", key, flags.Lookup(key).Value.String())
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	won := false
+	for _, s := range sources {
+		mark := " "
+		if s.set && !won {
+			mark, won = "*", true
+		}
+		value := s.value
+		if !s.set {
+			value = "<not set>"
+		}
+		fmt.Fprintf(w, "%s %s:\t%s\n", mark, s.name, value)
+	}
+	w.Flush()
+}
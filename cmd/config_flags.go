@@ -0,0 +1,114 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configCommands returns the commands under root having each flag bound to ENV and config file, by flag name
+func configCommands(root *cobra.Command) map[string][]*cobra.Command {
+	commands := map[string][]*cobra.Command{}
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		if hasAnnotation(c, annotationUnbound) {
+			return
+		}
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			if !unboundFlags[f.Name] {
+				commands[f.Name] = append(commands[f.Name], c)
+			}
+		})
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(root)
+	return commands
+}
+
+// configFlagValues converts the value v holds for the key of f to the values to set f to.
+//
+// Strings (e.g. ENV) are returned as they are, to be parsed as on the command line.
+// Lists and mappings (e.g. YAML sequences and mappings) do not round-trip through strings,
+// so they are returned as their items, replacing the value of f as a whole, and as their k=v pairs sorted by key.
+func configFlagValues(v *viper.Viper, f *pflag.Flag) (values []string, replace bool, err error) {
+	value := v.Get(f.Name)
+	if _, ok := f.Value.(pflag.SliceValue); ok {
+		if s, ok := value.(string); ok {
+			return nonEmpty(s), false, nil
+		}
+		return v.GetStringSlice(f.Name), true, nil
+	}
+	if f.Value.Type() == "stringToString" {
+		if s, ok := value.(string); ok {
+			return nonEmpty(s), false, nil
+		}
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, false, fmt.Errorf("invalid value, expected a mapping")
+		}
+		m := v.GetStringMapString(f.Name)
+		pairs := make([]string, 0, len(m))
+		for k, value := range m {
+			pairs = append(pairs, k+"="+value)
+		}
+		sort.Strings(pairs)
+		return pairs, false, nil
+	}
+	switch value.(type) {
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+		return nil, false, fmt.Errorf("invalid value, expected a %s", f.Value.Type())
+	}
+	return []string{v.GetString(f.Name)}, false, nil
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// setConfigFlag sets f to the value v holds for its key, marking it as changed
+func setConfigFlag(f *pflag.Flag, v *viper.Viper) error {
+	values, replace, err := configFlagValues(v, f)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 && !replace {
+		return nil
+	}
+	if replace {
+		err = f.Value.(pflag.SliceValue).Replace(values)
+	} else {
+		for _, value := range values {
+			if err = f.Value.Set(value); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value: %s", err.Error())
+	}
+	f.Changed = true
+	return nil
+}
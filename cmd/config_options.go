@@ -27,8 +27,14 @@ import (
 
 // ConfigOptions represent the persistent configuration flags of falcoctl.
 type ConfigOptions struct {
-	ConfigFile string
-	LogLevel   string `validate:"logrus" name:"log level" default:"info"`
+	ConfigFile    string
+	ConfigWatch   bool
+	LogLevel      string `validate:"logrus" name:"log level" default:"info"`
+	LogOutput     string `validate:"oneof=stderr stdout" name:"log output" default:"stderr"`
+	CI            string `validate:"omitempty,oneof=github" name:"ci"`
+	ExplainConfig string
+	NoEnv         bool
+	AuditLog      string `validate:"omitempty,filepath" name:"audit log"`
 }
 
 // NewConfigOptions creates an instance of ConfigOptions.
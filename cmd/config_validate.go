@@ -0,0 +1,111 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewConfigValidateCmd creates the `config validate` command
+func NewConfigValidateCmd(options CommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "validate <file>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate a config file without using it",
+		Long: `Validate a config file without using it.
+
+Every key must be the name of a flag falcoctl reads from its config file,
+and every value must be valid for that flag and for the commands having it.
+The configuration in use is not affected.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			file := args[0]
+			v := viper.New()
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err != nil {
+				return fmt.Errorf("could not read config file \"%s\": %s", file, err.Error())
+			}
+
+			// validate against a fresh command tree, not to alter the flags of this run
+			problems := validateConfigFile(New(nil), v)
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Fprintln(c.OutOrStdout(), p)
+				}
+				return fmt.Errorf("config file \"%s\" is invalid", file)
+			}
+			fmt.Fprintf(c.OutOrStdout(), "config file \"%s\" is valid\n", file)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// validateConfigFile sets the values of the config file loaded in v on the flags of the commands under root,
+// then validates the commands having them, returning the problems found sorted by key
+func validateConfigFile(root *cobra.Command, v *viper.Viper) []string {
+	commands := configCommands(root)
+
+	problems := map[string]string{}
+	keys := map[string]bool{}
+	for _, key := range v.AllKeys() {
+		// the keys of mappings (e.g. registry-regions.eu) belong to their flag
+		keys[strings.SplitN(key, ".", 2)[0]] = true
+	}
+	set := []*cobra.Command{}
+	for key := range keys {
+		if unboundFlags[key] {
+			problems[key] = "cannot be set in a config file"
+			continue
+		}
+		cs, ok := commands[key]
+		if !ok {
+			problems[key] = "unknown key"
+			continue
+		}
+		for _, c := range cs {
+			if err := setConfigFlag(c.Flags().Lookup(key), v); err != nil {
+				problems[key] = err.Error()
+				break
+			}
+			set = append(set, c)
+		}
+	}
+	validated := map[*cobra.Command]bool{}
+	for _, c := range set {
+		if validated[c] || c.PreRunE == nil {
+			continue
+		}
+		validated[c] = true
+		if err := c.PreRunE(c, nil); err != nil {
+			problems[c.CommandPath()] = err.Error()
+		}
+	}
+
+	lines := make([]string, 0, len(problems))
+	for key, p := range problems {
+		lines = append(lines, key+": "+p)
+	}
+	sort.Strings(lines)
+	return lines
+}
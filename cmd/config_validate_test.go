@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := ioutil.WriteFile(valid, []byte("registryurl: https://file.example.com/registry.yaml\nregistries:\n  - https://a.example.com/registry.yaml\nregistry-regions:\n  eu: https://eu.example.com/registry.yaml\nmin-score: 0.4\n"), 0600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+	out, err := execute(t, "config", "validate", valid)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "config file \""+valid+"\" is valid\n")
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := ioutil.WriteFile(invalid, []byte("min-score: 2\nregistry-keepalive: forever\nloglevel: debug\nunknown: value\nregistry-regions: eu\n"), 0600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+	out, err = execute(t, "config", "validate", invalid)
+	assert.Error(t, err, "config file \""+invalid+"\" is invalid")
+	// values valid for their flags are validated by the commands having them too
+	assert.Assert(t, strings.HasPrefix(out, `falcoctl search registry: --min-score must be between 0 and 1
+loglevel: cannot be set in a config file
+registry-keepalive: invalid value: time: invalid duration "forever"
+registry-regions: invalid value: eu must be formatted as key=value
+unknown: unknown key
+`), out)
+
+	// the registry url is validated as the registries are
+	badURL := filepath.Join(dir, "bad-url.yaml")
+	if err := ioutil.WriteFile(badURL, []byte("registryurl: not a url\n"), 0600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+	out, err = execute(t, "config", "validate", badURL)
+	assert.Error(t, err, "config file \""+badURL+"\" is invalid")
+	assert.Assert(t, strings.HasPrefix(out, `falcoctl search registry: invalid registry url "not a url": parse "not a url": invalid URI for request
+Error:`), out)
+}
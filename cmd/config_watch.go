@@ -0,0 +1,88 @@
+/*
+Copyright © 2019 The Falco Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	logger "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// configWatchDebounce is how long the config file must stay unchanged before rerunning
+const configWatchDebounce = 500 * time.Millisecond
+
+// watchConfig calls run every time file changes, once it stays unchanged for debounce.
+//
+// It watches the directory of file, so that editors replacing the file are noticed too,
+// and returns when ctx is done.
+func watchConfig(ctx context.Context, file string, debounce time.Duration, run func()) error {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		return err
+	}
+	logger.WithField("file", file).Info("watching config file")
+
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case event := <-watcher.Events:
+			if filepath.Clean(event.Name) != file || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			logger.WithField("file", file).Debugf("config file event: %s", event.Op)
+			timer.Reset(debounce)
+		case err := <-watcher.Errors:
+			logger.WithError(err).Warn("error watching config file")
+		case <-timer.C:
+			logger.WithField("file", file).Info("config file changed, running again")
+			run()
+		}
+	}
+}
+
+// checkConfigFile reads and validates file, as config validate does, without using it
+func checkConfigFile(file string) error {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	if problems := validateConfigFile(New(nil), v); len(problems) > 0 {
+		return fmt.Errorf("invalid config file: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gotest.tools/assert"
+)
+
+func TestWatchConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("registryurl: https://a.example.com/registry.yaml\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watchConfig(ctx, file, 300*time.Millisecond, func() { runs <- struct{}{} })
+	}()
+	// let the watcher start
+	time.Sleep(50 * time.Millisecond)
+
+	// other files in the same directory are ignored
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x: y\n"), 0644))
+	// a burst of writes runs once
+	for i := 0; i < 3; i++ {
+		assert.NilError(t, ioutil.WriteFile(file, []byte("registryurl: https://b.example.com/registry.yaml\n"), 0644))
+	}
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("config change did not run the command again")
+	}
+	time.Sleep(600 * time.Millisecond)
+	assert.Equal(t, len(runs), 0)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
+
+func TestExecuteWatched(t *testing.T) {
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("registryurl: https://a.example.com/registry.yaml\n"), 0644))
+
+	viper.Reset()
+	defer viper.Reset()
+	outs := make(chan string, 10)
+	newCmd := func(o *ConfigOptions) *cobra.Command {
+		c := New(o)
+		out := bytes.NewBufferString("")
+		c.SetOut(out)
+		c.SetArgs([]string{"search", "registry", "--explain-config", "registryurl", "-c", file, "--config-watch"})
+		c.PersistentPostRun = func(c *cobra.Command, args []string) { outs <- out.String() }
+		return c
+	}
+	next := func() string {
+		select {
+		case out := <-outs:
+			return out
+		case <-time.After(2 * time.Second):
+			t.Fatal("command did not run")
+			return ""
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- executeWatched(ctx, newCmd, 100*time.Millisecond)
+	}()
+	assert.Assert(t, strings.HasPrefix(next(), "registryurl: https://a.example.com/registry.yaml\n"))
+	// let the watcher start
+	time.Sleep(50 * time.Millisecond)
+
+	// the new command reads the changed config file
+	assert.NilError(t, ioutil.WriteFile(file, []byte("registryurl: https://b.example.com/registry.yaml\n"), 0644))
+	assert.Assert(t, strings.HasPrefix(next(), "registryurl: https://b.example.com/registry.yaml\n"))
+
+	// broken and invalid config files are reported, without running nor stopping to watch
+	for _, config := range []string{"registryurl: [unclosed\n", "registries: [not a url]\n"} {
+		assert.NilError(t, ioutil.WriteFile(file, []byte(config), 0644))
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, len(outs), 0)
+	}
+	assert.NilError(t, ioutil.WriteFile(file, []byte("registryurl: https://c.example.com/registry.yaml\n"), 0644))
+	assert.Assert(t, strings.HasPrefix(next(), "registryurl: https://c.example.com/registry.yaml\n"))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
@@ -24,6 +24,7 @@ import (
 func NewDeleteCmd(options CommandOptions) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "delete",
+		Annotations:           map[string]string{annotationMutating: "true"},
 		DisableFlagsInUseLine: true,
 		Short:                 "Delete a component with falcoctl",
 		Long:                  `Delete a component with falcoctl`,
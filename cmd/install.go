@@ -44,6 +44,7 @@ func NewInstallCmd(options CommandOptions) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:                   "install",
+		Annotations:           map[string]string{annotationMutating: "true"},
 		TraverseChildren:      true,
 		DisableFlagsInUseLine: true,
 		Short:                 "Install a component with falcoctl",
@@ -17,6 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/falcosecurity/falcoctl/pkg/tls"
 	logger "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,6 +44,7 @@ type TLSOptions struct {
 	name    string
 	path    string
 	days    int
+	verify  bool
 }
 
 // AddFlags adds flag to c
@@ -50,6 +55,7 @@ func (o *TLSOptions) AddFlags(c *cobra.Command) {
 	flags.StringVarP(&o.name, "name", "n", o.name, "The name to self sign the TLS cert with")
 	flags.IntVarP(&o.days, "days", "d", o.days, "The number of days to make self signed TLS cert valid for")
 	flags.StringVarP(&o.path, "path", "p", o.path, "The path to write the TLS cert to")
+	flags.BoolVar(&o.verify, "post-apply-verify", o.verify, "Read back the written TLS material and verify it matches the generated one")
 }
 
 // Validate validates the `install probe` command options
@@ -88,11 +94,18 @@ This command is a convenience to not only generate the TLS material, but also dr
 				logger.Fatal(err.Error())
 				return err
 			}
-			err = g.FlushToDisk(o.path)
+			err = flushToDisk(g, o.path)
 			if err != nil {
 				logger.Fatal(err.Error())
 				return err
 			}
+			if o.verify {
+				err = g.Verify(o.path)
+				if err != nil {
+					logger.Fatal(err.Error())
+					return err
+				}
+			}
 
 			return nil
 		},
@@ -102,3 +115,16 @@ This command is a convenience to not only generate the TLS material, but also dr
 
 	return cmd
 }
+
+// flushToDisk persists the TLS material to path, turning permission errors into an actionable message
+func flushToDisk(g *tls.GRPCTLS, path string) error {
+	return explainPermissionError(g.FlushToDisk(path), path)
+}
+
+// explainPermissionError tells how to recover from err, when writing into path was not permitted
+func explainPermissionError(err error, path string) error {
+	if err != nil && errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%v: run falcoctl as a user allowed to write into %q, or choose another directory with --path", err, path)
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/tls"
+	"gotest.tools/assert"
+)
+
+func TestFlushToDiskPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	dir, err := ioutil.TempDir("", "falcoctl-tls")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatalf("error making temp dir read-only: %v", err)
+	}
+	defer os.Chmod(dir, 0700)
+
+	g := tls.GRPCTLSGenerator(DefaultCertsCountry, DefaultCertsOrg, DefaultCertsName, DefaultCertsDays)
+	g.RSABits = 1024
+	if err := g.Generate(); err != nil {
+		t.Fatalf("error generating TLS material: %v", err)
+	}
+
+	path := filepath.Join(dir, "certs")
+	err = flushToDisk(g, path)
+	assert.ErrorContains(t, err, "permission denied")
+	assert.ErrorContains(t, err, `run falcoctl as a user allowed to write into "`+path+`", or choose another directory with --path`)
+}
+
+func TestExplainPermissionError(t *testing.T) {
+	path := "/etc/falco/certs"
+	denied := fmt.Errorf(`unable to write "ca.crt": %w`, &os.PathError{Op: "open", Path: path + "/ca.crt", Err: syscall.EACCES})
+	err := explainPermissionError(denied, path)
+	assert.Error(t, err, `unable to write "ca.crt": open /etc/falco/certs/ca.crt: permission denied: run falcoctl as a user allowed to write into "/etc/falco/certs", or choose another directory with --path`)
+
+	// other errors are returned as they are
+	other := errors.New("unable to ensure dir: no space left on device")
+	assert.Equal(t, explainPermissionError(other, path), other)
+	assert.NilError(t, explainPermissionError(nil, path))
+}
@@ -0,0 +1,56 @@
+package ci
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// GitHub is the name of the GitHub Actions CI integration
+const GitHub = "github"
+
+var _ logger.Hook = &GitHubHook{}
+
+// GitHubHook is a logrus hook emitting GitHub Actions workflow commands for warnings and errors
+type GitHubHook struct {
+	Out io.Writer
+}
+
+// Levels returns the levels the hook fires for
+func (h *GitHubHook) Levels() []logger.Level {
+	return []logger.Level{
+		logger.PanicLevel,
+		logger.FatalLevel,
+		logger.ErrorLevel,
+		logger.WarnLevel,
+	}
+}
+
+// Fire writes the entry as a `::error::` or `::warning::` workflow command
+func (h *GitHubHook) Fire(entry *logger.Entry) error {
+	command := "error"
+	if entry.Level == logger.WarnLevel {
+		command = "warning"
+	}
+
+	msg := entry.Message
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		msg += fmt.Sprintf(" %s=%v", k, entry.Data[k])
+	}
+
+	_, err := fmt.Fprintf(h.Out, "::%s::%s\n", command, escapeData(msg))
+	return err
+}
+
+// escapeData escapes the workflow command message as required by GitHub Actions
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
@@ -0,0 +1,24 @@
+package ci
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	logger "github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+func TestGitHubHook(t *testing.T) {
+	out := bytes.NewBufferString("")
+	l := logger.New()
+	l.SetOutput(bytes.NewBufferString(""))
+	l.AddHook(&GitHubHook{Out: out})
+
+	l.Info("nothing to annotate")
+	l.WithError(errors.New("boom")).Error("error validating config options")
+	l.Warn("100% done\nwith warnings")
+
+	assert.Equal(t, out.String(), "::error::error validating config options error=boom\n"+
+		"::warning::100%25 done%0Awith warnings\n")
+}
@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/falcosecurity/falcoctl/cmd/internal/ci"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -21,6 +25,20 @@ const (
 	configDir  = ".falcoctl"
 )
 
+// unboundFlags are the flags not to be bound to ENV and config file
+var unboundFlags = map[string]bool{
+	"config":         true,
+	"config-watch":   true,
+	"loglevel":       true,
+	"log-output":     true,
+	"ci":             true,
+	"explain-config": true,
+	"audit-log":      true,
+	"no-env":         true,
+	"help":           true,
+	"registryurl":    false,
+}
+
 func init() {
 	logger.SetFormatter(&logger.TextFormatter{
 		ForceColors:            true,
@@ -44,22 +62,46 @@ func New(configOptions *ConfigOptions) *cobra.Command {
 
 			// at this stage configOptions is bound to command line flags only
 			validateConfig(*configOptions)
-			initLogger(configOptions.LogLevel)
+			initLogger(configOptions.LogLevel, configOptions.LogOutput, c.OutOrStdout())
+			initCI(configOptions.CI, c.OutOrStdout())
 			logger.Debugf("running with args: %s", strings.Join(os.Args, " "))
 			initConfig(configOptions.ConfigFile)
+			if configOptions.ConfigWatch && viper.ConfigFileUsed() == "" {
+				logger.Fatal("--config-watch requires a config file")
+			}
 
 			// then bind all flags to ENV and config file
 			flags := c.Flags()
-			initEnv()
-			initFlags(flags, map[string]bool{
-				// exclude flags to be not bound to ENV and config file
-				"config":      true,
-				"loglevel":    true,
-				"help":        true,
-				"registryurl": false,
-			})
+			bound := !hasAnnotation(c, annotationUnbound)
+			var sources []configSource
+			if key := configOptions.ExplainConfig; key != "" {
+				var err error
+				if sources, err = configSources(flags, key, bound && !unboundFlags[key], !configOptions.NoEnv); err != nil {
+					logger.WithError(err).Fatal("error explaining configuration")
+				}
+			}
+			if !configOptions.NoEnv {
+				initEnv()
+			}
+			if bound {
+				initFlags(flags, unboundFlags)
+			}
 			//validateConfig(*configOptions) // enable if other flags were bound to configOptions
 			debugFlags(flags)
+			var audit *auditor
+			if configOptions.AuditLog != "" && configOptions.ExplainConfig == "" && hasAnnotation(c, annotationMutating) {
+				audit = newAuditor(configOptions.AuditLog, c, args)
+			}
+
+			if key := configOptions.ExplainConfig; key != "" {
+				explainConfig(c.OutOrStdout(), flags, key, sources)
+				// only explain the configuration, do not run the command
+				c.PreRunE, c.PreRun, c.RunE = nil, nil, nil
+				c.Run = func(c *cobra.Command, args []string) {}
+			}
+			if audit != nil {
+				audit.wrap(c)
+			}
 		},
 		Run: func(c *cobra.Command, args []string) {
 			c.Help()
@@ -68,10 +110,17 @@ func New(configOptions *ConfigOptions) *cobra.Command {
 
 	// Global flags
 	flags := rootCmd.PersistentFlags()
-	flags.StringVarP(&configOptions.ConfigFile, "config", "c", configOptions.ConfigFile, "Config file path (default "+filepath.Join("$HOME", configDir, configName+"yaml")+" if exists)")
+	flags.StringVarP(&configOptions.ConfigFile, "config", "c", configOptions.ConfigFile, "Config file path (default "+filepath.Join("$HOME", configDir, configName+".yaml")+" if exists)")
+	flags.BoolVar(&configOptions.ConfigWatch, "config-watch", configOptions.ConfigWatch, "Run the command again every time the config file changes, until interrupted")
 	flags.StringVarP(&configOptions.LogLevel, "loglevel", "l", configOptions.LogLevel, "Log level")
+	flags.StringVar(&configOptions.LogOutput, "log-output", configOptions.LogOutput, "Stream the logs are written to, one of: stderr, stdout")
+	flags.StringVar(&configOptions.ExplainConfig, "explain-config", configOptions.ExplainConfig, "Print the resolved value of the given configuration key and where it comes from, without running the command")
+	flags.StringVar(&configOptions.AuditLog, "audit-log", configOptions.AuditLog, "Append a JSON record of each install or delete operation to the given file")
+	flags.BoolVar(&configOptions.NoEnv, "no-env", configOptions.NoEnv, "Ignore the FALCOCTL_* ENV variables, reading the configuration from flags and config file only")
+	flags.StringVar(&configOptions.CI, "ci", configOptions.CI, "Emit warnings and errors as annotations for the given CI system (github)")
 
 	// Commands
+	rootCmd.AddCommand(NewConfigCmd(nil))
 	rootCmd.AddCommand(NewDeleteCmd(nil))
 	rootCmd.AddCommand(NewInstallCmd(NewInstallOptions()))
 	rootCmd.AddCommand(NewSearchCmd(NewSearchOptions()))
@@ -79,14 +128,66 @@ func New(configOptions *ConfigOptions) *cobra.Command {
 	return rootCmd
 }
 
+// Annotations marking commands, inherited by their subcommands
+const (
+	// annotationMutating marks the commands that change the state of the system
+	annotationMutating = "falcoctl/mutating"
+	// annotationUnbound marks the commands whose flags are not bound to ENV and config file
+	annotationUnbound = "falcoctl/unbound"
+)
+
+// hasAnnotation returns true if c or one of its ancestors is marked with annotation
+func hasAnnotation(c *cobra.Command, annotation string) bool {
+	for ; c != nil; c = c.Parent() {
+		if c.Annotations[annotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 // Execute creates the root command and runs it.
+//
+// With --config-watch, it creates and runs the root command again at every change of the config file.
 func Execute() {
 	ctx := WithSignals(context.Background())
-	if err := New(nil).ExecuteContext(ctx); err != nil {
+	if err := executeWatched(ctx, New, configWatchDebounce); err != nil {
 		logger.WithError(err).Fatal("error executing falcoctl")
 	}
 }
 
+// executeWatched runs the command created by newCmd.
+//
+// With --config-watch, it logs the errors of the command and runs a new one, with a fresh configuration,
+// every time the config file stays unchanged for debounce after a change, until ctx is done.
+func executeWatched(ctx context.Context, newCmd func(*ConfigOptions) *cobra.Command, debounce time.Duration) error {
+	configOptions := NewConfigOptions()
+	err := newCmd(configOptions).ExecuteContext(ctx)
+	if !configOptions.ConfigWatch {
+		return err
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("error executing falcoctl")
+	}
+	file := viper.ConfigFileUsed()
+	err = watchConfig(ctx, file, debounce, func() {
+		// a broken edit must not stop watching, while the command exits on config errors
+		if err := checkConfigFile(file); err != nil {
+			logger.WithField("file", file).WithError(err).Error("error running with config file")
+			return
+		}
+		viper.Reset()
+		if err := newCmd(nil).ExecuteContext(ctx); err != nil {
+			logger.WithError(err).Error("error executing falcoctl")
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("error watching config file: %v", err)
+	}
+	return nil
+}
+
 // WithSignals returns a copy of ctx with a new Done channel.
 // The returned context's Done channel is closed when a SIGKILL or SIGTERM signal is received.
 func WithSignals(ctx context.Context) context.Context {
@@ -129,13 +230,29 @@ func initEnv() {
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 }
 
-// initLogger configures the logger
-func initLogger(logLevel string) {
+// initLogger configures the logger, writing to out when logOutput is stdout
+func initLogger(logLevel, logOutput string, out io.Writer) {
 	lvl, err := logger.ParseLevel(logLevel)
 	if err != nil {
 		logger.Fatal(err)
 	}
 	logger.SetLevel(lvl)
+	if logOutput != "stdout" {
+		logger.SetOutput(os.Stderr)
+		return
+	}
+	logger.SetOutput(out)
+	// command results are always written to stdout
+	logger.Warn("logs are written to stdout together with the command results, use --log-output stderr to keep them apart")
+}
+
+// initCI configures the logger to annotate warnings and errors for the given CI system, if any
+func initCI(system string, out io.Writer) {
+	hooks := make(logger.LevelHooks)
+	if system == ci.GitHub {
+		hooks.Add(&ci.GitHubHook{Out: out})
+	}
+	logger.StandardLogger().ReplaceHooks(hooks)
 }
 
 // initConfig reads in config file, if any. Default location is ~/.falcoctl/config.yaml
@@ -178,13 +295,10 @@ func initConfig(configFile string) {
 func initFlags(flags *pflag.FlagSet, exclude map[string]bool) {
 	viper.BindPFlags(flags)
 	flags.VisitAll(func(f *pflag.Flag) {
-		if exclude[f.Name] {
+		if exclude[f.Name] || f.Changed || !viper.IsSet(f.Name) {
 			return
 		}
-		viper.SetDefault(f.Name, f.DefValue)
-		if v := viper.GetString(f.Name); v != f.DefValue {
-			flags.Set(f.Name, v)
-		}
+		setConfigFlag(f, viper.GetViper())
 	})
 }
 
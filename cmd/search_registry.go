@@ -1,33 +1,101 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"github.com/falcosecurity/falcoctl/cmd/internal/validate"
+	"github.com/falcosecurity/falcoctl/pkg/credhelper"
 	"github.com/falcosecurity/falcoctl/pkg/registry"
 	"github.com/go-playground/validator/v10"
+	logger "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Defaults
 const (
 	DefaultRegUrl   = "https://raw.githubusercontent.com/falcosecurity/plugins/master/registry.yaml"
 	DefaultPrintAll = false
+	DefaultOutput   = "yaml"
+	// DefaultTableWidth is the width compact tables fit in when not writing to a terminal
+	DefaultTableWidth = 80
+	// DefaultRegKeepAlive is the TCP keepalive period of the registry connections
+	DefaultRegKeepAlive = 30 * time.Second
+	// DefaultRegResponseHeaderTimeout bounds the wait for the registry response headers
+	DefaultRegResponseHeaderTimeout = 10 * time.Second
+	// DefaultRegHTTP2 enables HTTP/2 with the registries supporting it
+	DefaultRegHTTP2 = true
 )
 
+// Output formats of the `search registry` command
+var searchRegOutputs = []string{"yaml", "template", "table", "compact-table", "markdown", "env"}
+
 var _ CommandOptions = &SearchRegOptions{}
 
 // TLSOptions represents the `install tls` command options
 type SearchRegOptions struct {
-	registry string `validate:"registryurl" name:"registry url" default:"https://raw.githubusercontent.com/falcosecurity/plugins/master/registry.yaml"`
-	printall bool
+	registry      string `validate:"registryurl" name:"registry url" default:"https://raw.githubusercontent.com/falcosecurity/plugins/master/registry.yaml"`
+	printall      bool
+	aggregateBy   string
+	countOnly     bool
+	requestID     string
+	fuzzy         bool
+	minScore      float64
+	output        string
+	template      string
+	language      string
+	registries    []string
+	byRegistry    bool
+	hostname      string
+	keepAlive     time.Duration
+	headerTimeout time.Duration
+	http2         bool
+	authHelper    string
+	columns       []string
+	dump          string
+	region        string
+	regions       map[string]string
+	noHeaders     bool
 }
 
 // AddFlags adds flag to c
 func (o *SearchRegOptions) AddFlags(c *cobra.Command) {
 	flags := c.Flags()
 	flags.StringVarP(&o.registry, "registryurl", "r", o.registry, "Registry url to search")
+	flags.StringVar(&o.region, "registry-region", o.region, "Region whose registry url, among --registry-regions, to search instead of --registryurl")
+	flags.StringToStringVar(&o.regions, "registry-regions", o.regions, "Registry urls by region, as region=url pairs")
+	flags.StringSliceVar(&o.registries, "registries", o.registries, "Registry urls to search instead of the default one, merged with --registryurl when given")
+	flags.BoolVar(&o.byRegistry, "group-by-registry", o.byRegistry, "Search each registry on its own and print its entries under the registry url")
 	flags.BoolVarP(&o.printall, "all", "a", o.printall, "Print all the entries")
+	flags.BoolVar(&o.fuzzy, "fuzzy", o.fuzzy, "Fuzzy match the arguments against names and descriptions, best matches first")
+	flags.Float64Var(&o.minScore, "min-score", o.minScore, "Minimum score, between 0 and 1, of a fuzzy match")
+	flags.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: "+strings.Join(searchRegOutputs, ", "))
+	flags.StringSliceVar(&o.columns, "columns", o.columns, "Columns to show, in order, when --output is a table, or all (default "+strings.ToLower(strings.Join(registry.DefaultColumns, ","))+")")
+	flags.BoolVar(&o.noHeaders, "no-headers", o.noHeaders, "Omit the header row when --output is a table, leaving its cells empty with markdown")
+	flags.StringVar(&o.template, "template", o.template, "Built-in template to render the entries with when --output is template, one of: "+strings.Join(registry.TemplateNames(), ", "))
+	flags.BoolVar(&o.countOnly, "count-only", o.countOnly, "Print only the number of matching entries, with --output yaml")
+	flags.StringVar(&o.aggregateBy, "aggregate-by", o.aggregateBy, "Group the entries by one of: "+strings.Join(registry.AggregateFields, ", "))
+	flags.StringVar(&o.language, "accept-language", o.language, "Language to request localized entries in (defaults to the system locale, or en)")
+	flags.StringVar(&o.dump, "dump-registry", o.dump, "Write the raw registry documents, as fetched and separated by ---, to the given file (- for stdout) before processing them")
+	flags.StringVar(&o.requestID, "registry-request-id-header", o.requestID, "Header to send a unique request id in on every registry request")
+	flags.StringVar(&o.hostname, "registry-hostname-override", o.hostname, "Hostname to send as TLS server name and Host header instead of the one in the registry url")
+	flags.DurationVar(&o.keepAlive, "registry-keepalive", o.keepAlive, "TCP keepalive period of the registry connections, 0 disables it")
+	flags.StringVar(&o.authHelper, "registry-auth-helper", o.authHelper, "Docker credential helper to get the registry credentials from, running docker-credential-<name>")
+	flags.BoolVar(&o.http2, "registry-http2", o.http2, "Use HTTP/2 with the registries supporting it, disable to fall back to HTTP/1.1")
+	flags.DurationVar(&o.headerTimeout, "registry-response-header-timeout", o.headerTimeout, "Time to wait for the registry response headers once the request is sent, 0 waits forever")
 }
 
 // Validate validates the `search registry` command options
@@ -35,15 +103,455 @@ func (o *SearchRegOptions) Validate(c *cobra.Command, args []string) error {
 	if err := validate.V.Struct(o); err != nil {
 		return err.(validator.ValidationErrors)
 	}
+	// the validator skips unexported fields, check them explicitly
+	for _, u := range append([]string{o.registry}, o.registries...) {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return fmt.Errorf("invalid registry url \"%s\": %s", u, err.Error())
+		}
+	}
+	if o.hostname != "" {
+		valid := validate.V.Var(o.serverName(), "hostname_rfc1123") == nil
+		// a Host header may have a port
+		if _, port, err := net.SplitHostPort(o.hostname); err == nil {
+			n, err := strconv.ParseUint(port, 10, 16)
+			valid = valid && err == nil && n > 0
+		}
+		if !valid {
+			return fmt.Errorf("invalid registry hostname override \"%s\", must be a valid hostname, optionally followed by :port", o.hostname)
+		}
+	}
+	for region, u := range o.regions {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return fmt.Errorf("invalid registry url \"%s\" for region \"%s\": %s", u, region, err.Error())
+		}
+	}
+	if _, err := o.regionURL(); err != nil {
+		return err
+	}
+	if o.keepAlive < 0 {
+		return fmt.Errorf("--registry-keepalive must not be negative")
+	}
+	if o.headerTimeout < 0 {
+		return fmt.Errorf("--registry-response-header-timeout must not be negative")
+	}
+	if o.minScore < 0 || o.minScore > 1 {
+		return fmt.Errorf("--min-score must be between 0 and 1")
+	}
+	switch o.output {
+	case "yaml":
+		if o.template != "" {
+			return fmt.Errorf("--template requires --output template")
+		}
+	case "table", "compact-table", "markdown":
+		if o.template != "" {
+			return fmt.Errorf("--template requires --output template")
+		}
+	case "env":
+		if o.template != "" {
+			return fmt.Errorf("--template requires --output template")
+		}
+		if o.aggregateBy != "" {
+			return fmt.Errorf("--aggregate-by requires --output yaml, table, compact-table or markdown")
+		}
+	case "template":
+		if o.template == "" {
+			return fmt.Errorf("--output template requires --template, available templates: %s", strings.Join(registry.TemplateNames(), ", "))
+		}
+		if err := registry.ValidateTemplate(o.template); err != nil {
+			return err
+		}
+		if o.aggregateBy != "" {
+			return fmt.Errorf("--aggregate-by requires --output yaml, table, compact-table or markdown")
+		}
+	default:
+		return fmt.Errorf("unknown output format \"%s\", must be one of: %s", o.output, strings.Join(searchRegOutputs, ", "))
+	}
+	isTable := o.output == "table" || o.output == "compact-table" || o.output == "markdown"
+	if o.noHeaders && !isTable {
+		return fmt.Errorf("--no-headers requires --output table, compact-table or markdown")
+	}
+	if len(o.columns) > 0 {
+		if !isTable {
+			return fmt.Errorf("--columns requires --output table, compact-table or markdown")
+		}
+		if len(o.columns) > 1 || o.columns[0] != "all" {
+			for _, name := range o.columns {
+				if err := registry.ValidateColumn(name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if o.output == "env" && o.byRegistry {
+		return fmt.Errorf("--group-by-registry cannot be used with --output env")
+	}
+	// the other outputs would ignore either the format or the count
+	if o.countOnly && o.output != "yaml" {
+		return fmt.Errorf("--count-only cannot be used with --output %s", o.output)
+	}
+	if o.countOnly && o.aggregateBy != "" {
+		return fmt.Errorf("--count-only and --aggregate-by cannot be used together")
+	}
+	if o.aggregateBy != "" {
+		if err := registry.ValidateAggregateField(o.aggregateBy); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // NewRegOptions instantiates the `search registry` command options
 func NewSearchRegptions() *SearchRegOptions {
 	return &SearchRegOptions{
-		registry: DefaultRegUrl,
-		printall: DefaultPrintAll,
+		registry:      DefaultRegUrl,
+		printall:      DefaultPrintAll,
+		minScore:      registry.DefaultMinScore,
+		output:        DefaultOutput,
+		keepAlive:     DefaultRegKeepAlive,
+		headerTimeout: DefaultRegResponseHeaderTimeout,
+		http2:         DefaultRegHTTP2,
+	}
+}
+
+// urls returns the registries to search.
+//
+// The primary registry is the one of --registry-region if given, --registryurl otherwise.
+// When --registries is set, the primary registry is not searched unless explicitly given.
+func (o *SearchRegOptions) urls(c *cobra.Command) []string {
+	primary, explicit := o.registry, c.Flags().Changed("registryurl")
+	if o.region != "" {
+		primary, _ = o.regionURL()
+		explicit = true
+	}
+	if len(o.registries) == 0 {
+		return []string{primary}
+	}
+	urls := append([]string{}, o.registries...)
+	if explicit {
+		for _, url := range urls {
+			if url == primary {
+				return urls
+			}
+		}
+		urls = append(urls, primary)
+	}
+	return urls
+}
+
+// regionURL returns the registry url of --registry-region, matching the configured regions case-insensitively
+func (o *SearchRegOptions) regionURL() (string, error) {
+	if o.region == "" {
+		return "", nil
+	}
+	regions := make([]string, 0, len(o.regions))
+	for region, url := range o.regions {
+		// viper lowercases the keys of the config file mappings
+		if strings.EqualFold(region, o.region) {
+			return url, nil
+		}
+		regions = append(regions, region)
+	}
+	if len(regions) == 0 {
+		return "", fmt.Errorf("unknown registry region \"%s\", no regions configured with --registry-regions", o.region)
+	}
+	sort.Strings(regions)
+	return "", fmt.Errorf("unknown registry region \"%s\", configured regions: %s", o.region, strings.Join(regions, ", "))
+}
+
+// registryTransport is the transport the registry clients are derived from
+var registryTransport = http.DefaultTransport.(*http.Transport)
+
+// client returns the HTTP client to fetch the registries with
+func (o *SearchRegOptions) client() *http.Client {
+	transport := registryTransport.Clone()
+	transport.DialContext = o.dialer().DialContext
+	transport.ResponseHeaderTimeout = o.headerTimeout
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if o.hostname != "" {
+		transport.TLSClientConfig.ServerName = o.serverName()
+	}
+	transport.ForceAttemptHTTP2 = o.http2
+	if !o.http2 {
+		// a non-nil empty map disables the HTTP/2 upgrade of TLS connections
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// serverName returns the host of --registry-hostname-override, without the port the Host header may have
+func (o *SearchRegOptions) serverName() string {
+	if host, _, err := net.SplitHostPort(o.hostname); err == nil {
+		return host
+	}
+	return o.hostname
+}
+
+// dialer returns the dialer of the registry connections
+func (o *SearchRegOptions) dialer() *net.Dialer {
+	keepAlive := o.keepAlive
+	if keepAlive == 0 {
+		// a zero keepalive would enable the net package default period
+		keepAlive = -1
+	}
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}
+}
+
+// registryDump writes the registry documents, as fetched, to w as a single YAML stream
+type registryDump struct {
+	w         io.Writer
+	documents int
+}
+
+// write appends data to the stream, separating it from the previous document
+func (d *registryDump) write(data []byte) error {
+	if d.documents > 0 && !bytes.HasPrefix(data, []byte("---")) {
+		if _, err := io.WriteString(d.w, "---\n"); err != nil {
+			return err
+		}
+	}
+	d.documents++
+	if _, err := d.w.Write(data); err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		_, err := io.WriteString(d.w, "\n")
+		return err
+	}
+	return nil
+}
+
+// fetch downloads and loads the registry at url, writing the document as fetched to dump if not nil
+func (o *SearchRegOptions) fetch(ctx context.Context, client *http.Client, dump *registryDump, url string) (*registry.Registry, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to GET from URL \"%s\": %s", url, err.Error())
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	language := o.language
+	if language == "" {
+		language = systemLanguage()
+	}
+	req.Header.Set("Accept-Language", language)
+	if o.hostname != "" {
+		req.Host = o.hostname
+	}
+	if o.authHelper != "" {
+		if err := o.authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+	if o.requestID != "" {
+		id, err := newRequestID()
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate request id: %s", err.Error())
+		}
+		req.Header.Set(o.requestID, id)
+		logger.WithField(o.requestID, id).WithField("url", url).Debug("sending registry request")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to GET from URL \"%s\": %s", url, err.Error())
+	}
+	body := resp.Body
+	defer resp.Body.Close()
+	if dump != nil {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to GET from URL \"%s\": %s", url, err.Error())
+		}
+		if err := dump.write(data); err != nil {
+			return nil, fmt.Errorf("unable to dump registry: %s", err.Error())
+		}
+		body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+
+	reg, err := registry.LoadRegistry(&body)
+	if err != nil {
+		return nil, fmt.Errorf("could not load registry: %s", err.Error())
+	}
+	return reg, nil
+}
+
+// authenticate sets the credentials the auth helper stores for the registry host on req, if any
+func (o *SearchRegOptions) authenticate(req *http.Request) error {
+	helper := credhelper.New(o.authHelper)
+	creds, err := helper.Get(req.URL.Host)
+	if err == credhelper.ErrNotFound {
+		logger.WithField("host", req.URL.Host).Debugf("no credentials from %s, sending anonymous request", helper.Program())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get registry credentials: %s", err.Error())
+	}
+	if creds.Username == credhelper.TokenUsername {
+		req.Header.Set("Authorization", "Bearer "+creds.Secret)
+	} else {
+		req.SetBasicAuth(creds.Username, creds.Secret)
+	}
+	logger.WithField("host", req.URL.Host).WithField("username", creds.Username).Debug("using registry credentials")
+	return nil
+}
+
+// search returns the plugins of reg matching args, or all of them with --all
+func (o *SearchRegOptions) search(reg *registry.Registry, args []string) *registry.Plugins {
+	switch {
+	case o.printall:
+		return &reg.Plugins
+	case o.fuzzy:
+		return reg.SearchFuzzy(args, o.minScore)
+	default:
+		return reg.SearchByKeywords(args)
+	}
+}
+
+// renderByRegistry searches each registry on its own and formats its plugins under its url.
+//
+// The yaml output maps each url to its results, the other outputs print a section per url.
+func (o *SearchRegOptions) renderByRegistry(urls []string, regs []*registry.Registry, args []string, width int) (string, error) {
+	if o.output == "yaml" {
+		results := yaml.MapSlice{}
+		for i, url := range urls {
+			plugins := o.search(regs[i], args)
+			var result interface{} = plugins
+			switch {
+			case o.countOnly:
+				result = plugins.Count()
+			case o.aggregateBy != "":
+				groups, err := plugins.AggregateBy(o.aggregateBy)
+				if err != nil {
+					return "", err
+				}
+				result = groups
+			}
+			results = append(results, yaml.MapItem{Key: url, Value: result})
+		}
+		bytes, err := yaml.Marshal(results)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+	sections := make([]string, len(urls))
+	for i, url := range urls {
+		output, err := o.render(o.search(regs[i], args), width)
+		if err != nil {
+			return "", err
+		}
+		sections[i] = fmt.Sprintf("%s:\n%s", url, output)
 	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// render formats the plugins according to the output options, fitting compact tables in width
+func (o *SearchRegOptions) render(plugins *registry.Plugins, width int) (string, error) {
+	switch {
+	case o.countOnly:
+		return strconv.Itoa(plugins.Count()), nil
+	case o.aggregateBy != "":
+		groups, err := plugins.AggregateBy(o.aggregateBy)
+		if err != nil {
+			return "", err
+		}
+		if o.output == "yaml" {
+			return groups.ToString()
+		}
+		return o.renderGroups(groups, width)
+	case o.output == "template":
+		return plugins.ToTemplate(o.template)
+	case o.output == "table":
+		return plugins.ToTable(registry.TableOptions{Columns: o.tableColumns(), Padding: 3, NoHeaders: o.noHeaders})
+	case o.output == "env":
+		return plugins.ToEnv()
+	case o.output == "compact-table":
+		return plugins.ToTable(registry.TableOptions{Columns: o.tableColumns(), Padding: 1, Width: width, NoHeaders: o.noHeaders})
+	case o.output == "markdown":
+		return plugins.ToMarkdown(registry.TableOptions{Columns: o.tableColumns(), NoHeaders: o.noHeaders})
+	default:
+		return plugins.ToString()
+	}
+}
+
+// renderGroups formats each group under its value, sorted by value, as renderByRegistry does with registries
+func (o *SearchRegOptions) renderGroups(groups registry.Groups, width int) (string, error) {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	flat := *o
+	flat.aggregateBy = ""
+	sections := make([]string, len(keys))
+	for i, key := range keys {
+		output, err := flat.render(&groups[key].Plugins, width)
+		if err != nil {
+			return "", err
+		}
+		sections[i] = fmt.Sprintf("%s:\n%s", key, output)
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// tableColumns returns the columns of the table outputs
+func (o *SearchRegOptions) tableColumns() []string {
+	switch {
+	case len(o.columns) == 0:
+		return registry.DefaultColumns
+	case len(o.columns) == 1 && o.columns[0] == "all":
+		return registry.ColumnNames()
+	default:
+		return o.columns
+	}
+}
+
+// tableWidth returns the width compact tables written to out must fit in.
+//
+// The COLUMNS environment variable takes precedence over the terminal width.
+func tableWidth(out io.Writer) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if width := terminalWidth(out); width > 0 {
+		return width
+	}
+	return DefaultTableWidth
+}
+
+// systemLanguage returns the language tag of the system locale, defaulting to en
+func systemLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(env)
+		if locale == "" {
+			continue
+		}
+		// e.g. it_IT.UTF-8@euro -> it-IT
+		locale = strings.SplitN(locale, ".", 2)[0]
+		locale = strings.SplitN(locale, "@", 2)[0]
+		if locale == "C" || locale == "POSIX" {
+			break
+		}
+		return strings.ReplaceAll(locale, "_", "-")
+	}
+	return "en"
+}
+
+// newRequestID returns a random (version 4) UUID
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
 }
 
 func NewSearchRegistryCmd(options CommandOptions) *cobra.Command {
@@ -56,32 +564,49 @@ func NewSearchRegistryCmd(options CommandOptions) *cobra.Command {
 		Long:                  `Search a plugin inside the official Falco registry`,
 		PreRunE:               o.Validate,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var output string
 			if !o.printall && len(args) == 0 {
 				return fmt.Errorf("please provide one or more arguments or --all/-a flag")
 			}
-			resp, err := http.Get(o.registry)
-			if err != nil {
-				return fmt.Errorf("unable to GET from URL \"%s\": %s", o.registry, err.Error())
+			urls := o.urls(cmd)
+			regs := make([]*registry.Registry, len(urls))
+			client := o.client()
+			var dump *registryDump
+			switch o.dump {
+			case "":
+			case "-":
+				dump = &registryDump{w: cmd.OutOrStdout()}
+			default:
+				f, err := os.OpenFile(o.dump, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+				if err != nil {
+					return fmt.Errorf("unable to dump registry: %s", err.Error())
+				}
+				defer f.Close()
+				dump = &registryDump{w: f}
 			}
-			body := resp.Body
-			defer body.Close()
-
-			reg, err := registry.LoadRegistry(&body)
-			if err != nil {
-				return fmt.Errorf("could not load registry: %s", err.Error())
+			for i, url := range urls {
+				r, err := o.fetch(cmd.Context(), client, dump, url)
+				if err != nil {
+					return err
+				}
+				regs[i] = r
 			}
 
-			if o.printall {
-				output, err = reg.Plugins.ToString()
+			var output string
+			var err error
+			width := tableWidth(cmd.OutOrStdout())
+			if o.byRegistry {
+				output, err = o.renderByRegistry(urls, regs, args, width)
 			} else {
-				plugins := reg.SearchByKeywords(args)
-				output, err = plugins.ToString()
+				reg := &registry.Registry{}
+				for _, r := range regs {
+					reg.Merge(r)
+				}
+				output, err = o.render(o.search(reg, args), width)
 			}
 			if err != nil {
 				return err
 			}
-			fmt.Println(output)
+			fmt.Fprintln(cmd.OutOrStdout(), output)
 			return nil
 		},
 	}
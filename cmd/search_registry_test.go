@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falcoctl/pkg/registry"
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+// testRegistryFixture is the registry served to the tests, shared with the registry package
+const testRegistryFixture = "../pkg/registry/testdata/registry.yaml"
+
+// newTestRegistryHandler serves the registry fixture, calling hooks with each request before responding
+func newTestRegistryHandler(t *testing.T, hooks ...func(r *http.Request)) http.Handler {
+	reg, err := ioutil.ReadFile(testRegistryFixture)
+	if err != nil {
+		t.Fatalf("registry fixture not found: %v", err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, hook := range hooks {
+			hook(r)
+		}
+		w.Write(reg)
+	})
+}
+
+func newTestRegistryServer(t *testing.T, hooks ...func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(newTestRegistryHandler(t, hooks...))
+}
+
+func runSearchRegistry(t *testing.T, args ...string) (string, error) {
+	return execute(t, append([]string{"search", "registry"}, args...)...)
+}
+
+func TestSearchRegistryCountOnly(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+
+	// "json" and "cloudtrail" both match the cloudtrail source, which must be counted once
+	out, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "json", "cloudtrail")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "2\n")
+
+	out, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "nomatch")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "0\n")
+
+	for _, output := range []string{"env", "table", "compact-table", "markdown"} {
+		_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "-o", output)
+		assert.Error(t, err, "--count-only cannot be used with --output "+output)
+	}
+	_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "-o", "template", "--template", "short")
+	assert.Error(t, err, "--count-only cannot be used with --output template")
+}
+
+func TestSearchRegistryRequestIDHeader(t *testing.T) {
+	ids := []string{}
+	s := newTestRegistryServer(t, func(r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+	})
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-request-id-header", "X-Request-Id")
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, len(ids), 2)
+	assert.Assert(t, ids[0] != "")
+	assert.Assert(t, ids[1] != "")
+	assert.Assert(t, ids[0] != ids[1])
+
+	_, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, ids[2], "")
+}
+
+func TestSearchRegistryOutputTemplate(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "template", "--template", "short", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `k8saudit (source): Read Kubernetes Audit Events and monitor Kubernetes Clusters
+cloudtrail (source): Reads Cloudtrail JSON logs from files/S3 and injects as events
+dummy (source): Reference plugin used to document interface
+json (extractor): Extract values from any JSON payload
+`)
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "-o", "template", "--template", "oci-ref", "--all")
+	assert.Error(t, err, `unknown template "oci-ref", available templates: detailed, short, url`)
+}
+
+func TestSearchRegistryOutputCompactTable(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	os.Setenv("COLUMNS", "60")
+	defer os.Unsetenv("COLUMNS")
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "compact-table", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `NAME       TYPE      SOURCES                  DESCRIPTION
+cloudtrail source    aws_cloudtrail           Reads Cloudtr…
+json       extractor aws_cloudtrail,k8s_audit Extract value…
+`)
+
+	// each group is a table of its own
+	out, err = runSearchRegistry(t, "-r", s.URL, "-o", "compact-table", "--columns", "name,type", "--aggregate-by", "type", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `extractor:
+NAME TYPE
+json extractor
+
+source:
+NAME       TYPE
+cloudtrail source
+`)
+}
+
+func TestSearchRegistryAggregateByMarkdown(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "markdown", "--columns", "name,license", "--aggregate-by", "license", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `Apache-2.0:
+| NAME | LICENSE |
+| --- | --- |
+| k8saudit | Apache-2.0 |
+| cloudtrail | Apache-2.0 |
+| json | Apache-2.0 |
+
+MIT:
+| NAME | LICENSE |
+| --- | --- |
+| dummy | MIT |
+`)
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "-o", "env", "--aggregate-by", "type", "json")
+	assert.Error(t, err, "--aggregate-by requires --output yaml, table, compact-table or markdown")
+}
+
+func TestSearchRegistryAcceptLanguage(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		description := "Reads Cloudtrail JSON logs"
+		if r.Header.Get("Accept-Language") == "it-IT" {
+			description = "Legge i log JSON di Cloudtrail"
+		}
+		w.Write([]byte("plugins:\n  source:\n    - name: cloudtrail\n      description: " + description + "\n"))
+	}))
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "template", "--template", "short", "--all", "--accept-language", "it-IT")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "cloudtrail (source): Legge i log JSON di Cloudtrail\n")
+
+	for k, v := range map[string]string{"LC_ALL": "", "LC_MESSAGES": "", "LANG": "it_IT.UTF-8"} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+	out, err = runSearchRegistry(t, "-r", s.URL, "-o", "template", "--template", "short", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "cloudtrail (source): Legge i log JSON di Cloudtrail\n")
+
+	os.Setenv("LANG", "C")
+	out, err = runSearchRegistry(t, "-r", s.URL, "-o", "template", "--template", "short", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "cloudtrail (source): Reads Cloudtrail JSON logs\n")
+}
+
+func TestSearchRegistryRegistriesFromConfig(t *testing.T) {
+	served := map[string]int{}
+	newServer := func(name, plugin string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served[name]++
+			w.Write([]byte("plugins:\n  source:\n    - name: " + plugin + "\n"))
+		}))
+	}
+	first, second, flag := newServer("first", "k8saudit"), newServer("second", "cloudtrail"), newServer("flag", "dummy")
+	defer first.Close()
+	defer second.Close()
+	defer flag.Close()
+
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	config := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(config, []byte("registries:\n  - "+first.URL+"\n  - "+second.URL+"\n"), 0600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+
+	out, err := execute(t, "-c", config, "search", "registry", "-o", "template", "--template", "short", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "k8saudit (source): \ncloudtrail (source): \n")
+	assert.DeepEqual(t, served, map[string]int{"first": 1, "second": 1})
+
+	out, err = execute(t, "-c", config, "search", "registry", "-o", "template", "--template", "short", "--all", "-r", flag.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "k8saudit (source): \ncloudtrail (source): \ndummy (source): \n")
+	assert.DeepEqual(t, served, map[string]int{"first": 2, "second": 2, "flag": 1})
+
+	// ENV lists are comma separated, as on the command line
+	os.Setenv("FALCOCTL_REGISTRIES", first.URL+","+second.URL)
+	defer os.Unsetenv("FALCOCTL_REGISTRIES")
+	out, err = execute(t, "search", "registry", "-o", "template", "--template", "short", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "k8saudit (source): \ncloudtrail (source): \n")
+	assert.DeepEqual(t, served, map[string]int{"first": 3, "second": 3, "flag": 1})
+
+	out, err = execute(t, "search", "registry", "--explain-config", "registries")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out, "registries: ["+first.URL+","+second.URL+"]\n"), out)
+}
+
+func TestSearchRegistryGroupByRegistry(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`plugins:
+  source:
+    - id: 999
+      source: okta
+      name: okta
+      description: Okta Log Events
+      license: Apache-2.0
+  extractor:
+    - sources:
+        - okta
+      name: json
+      description: Extract values from any JSON payload
+      license: Apache-2.0
+`))
+	}))
+	defer other.Close()
+
+	registries := s.URL + "," + other.URL
+	out, err := runSearchRegistry(t, "--registries", registries, "--group-by-registry", "--count-only", "json", "okta")
+	assert.NilError(t, err)
+	assert.Equal(t, out, s.URL+": 2\n"+other.URL+": 2\n\n")
+
+	out, err = runSearchRegistry(t, "--registries", registries, "--group-by-registry", "-o", "template", "--template", "short", "okta")
+	assert.NilError(t, err)
+	assert.Equal(t, out, s.URL+":\n\n\n"+other.URL+":\nokta (source): Okta Log Events\n")
+
+	out, err = runSearchRegistry(t, "--registries", registries, "--group-by-registry", "--aggregate-by", "type", "json")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Index(out, s.URL+":\n  extractor:\n    count: 1") == 0, out)
+	assert.Assert(t, strings.Contains(out, other.URL+":\n  extractor:\n    count: 1"), out)
+}
+
+func TestSearchRegistryHostnameOverride(t *testing.T) {
+	var host, serverName string
+	s := httptest.NewTLSServer(newTestRegistryHandler(t, func(r *http.Request) {
+		host, serverName = r.Host, r.TLS.ServerName
+	}))
+	defer s.Close()
+
+	// trust the test server certificate, valid for example.com too
+	defer func(t *http.Transport) { registryTransport = t }(registryTransport)
+	registryTransport = s.Client().Transport.(*http.Transport)
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-hostname-override", "example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+	assert.Equal(t, host, "example.com")
+	assert.Equal(t, serverName, "example.com")
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, host, strings.TrimPrefix(s.URL, "https://"))
+	assert.Equal(t, serverName, "")
+
+	// the Host header keeps the port, the TLS server name does not
+	_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-hostname-override", "example.com:8443")
+	assert.NilError(t, err)
+	assert.Equal(t, host, "example.com:8443")
+	assert.Equal(t, serverName, "example.com")
+
+	for _, hostname := range []string{"x.io", "1reg.example.com", "registry:443"} {
+		o := NewSearchRegptions()
+		o.hostname = hostname
+		assert.NilError(t, o.Validate(nil, nil), hostname)
+	}
+	for _, hostname := range []string{"not a host!!", "example.com:", "example.com:https", "example.com:0", "example.com:65536"} {
+		_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-hostname-override", hostname)
+		assert.Error(t, err, `invalid registry hostname override "`+hostname+`", must be a valid hostname, optionally followed by :port`)
+	}
+}
+
+func TestSearchRegistryKeepAlive(t *testing.T) {
+	o := NewSearchRegptions()
+	c := NewSearchRegistryCmd(o)
+
+	assert.Equal(t, o.dialer().KeepAlive, DefaultRegKeepAlive)
+
+	assert.NilError(t, c.Flags().Set("registry-keepalive", "10s"))
+	assert.Equal(t, o.dialer().KeepAlive, 10*time.Second)
+
+	// a negative net.Dialer keepalive disables it
+	assert.NilError(t, c.Flags().Set("registry-keepalive", "0"))
+	assert.Assert(t, o.dialer().KeepAlive < 0)
+
+	_, err := runSearchRegistry(t, "--all", "--registry-keepalive", "-1s")
+	assert.Error(t, err, "--registry-keepalive must not be negative")
+}
+
+func TestSearchRegistryNoEnv(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	os.Setenv("FALCOCTL_COUNT_ONLY", "true")
+	defer os.Unsetenv("FALCOCTL_COUNT_ONLY")
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+
+	out, err = runSearchRegistry(t, "-r", s.URL, "--all", "--no-env")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out, "source:\n"), out)
+}
+
+func TestSearchRegistryResponseHeaderTimeout(t *testing.T) {
+	s := newTestRegistryServer(t, func(r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer s.Close()
+
+	_, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-response-header-timeout", "50ms")
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+}
+
+func TestSearchRegistryHTTP2(t *testing.T) {
+	o := NewSearchRegptions()
+	c := NewSearchRegistryCmd(o)
+
+	transport := o.client().Transport.(*http.Transport)
+	assert.Assert(t, transport.ForceAttemptHTTP2)
+	assert.Assert(t, strings.Join(transport.TLSClientConfig.NextProtos, ",") != "http/1.1")
+
+	assert.NilError(t, c.Flags().Set("registry-http2", "false"))
+	transport = o.client().Transport.(*http.Transport)
+	assert.Assert(t, !transport.ForceAttemptHTTP2)
+	assert.Assert(t, transport.TLSNextProto != nil)
+	assert.Equal(t, len(transport.TLSNextProto), 0)
+	assert.DeepEqual(t, transport.TLSClientConfig.NextProtos, []string{"http/1.1"})
+}
+
+func TestSearchRegistryAuthHelper(t *testing.T) {
+	var auth string
+	s := newTestRegistryServer(t, func(r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	})
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	dir, err := ioutil.TempDir("", "falcoctl-credhelper")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	helper := `#!/bin/sh
+read server
+if [ "$1" = get ] && [ "$server" = "` + host + `" ]; then
+	echo '{"ServerURL":"'$server'","Username":"falco","Secret":"s3cr3t"}'
+else
+	echo "credentials not found in native keychain"; exit 1
+fi
+`
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(helper), 0755))
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	defer os.Setenv("PATH", path)
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-auth-helper", "fake")
+	assert.NilError(t, err)
+	assert.Equal(t, auth, "Basic ZmFsY286czNjcjN0")
+
+	// no credentials for the host, the request is anonymous
+	_, err = runSearchRegistry(t, "-r", strings.Replace(s.URL, "127.0.0.1", "localhost", 1), "--count-only", "--all", "--registry-auth-helper", "fake")
+	assert.NilError(t, err)
+	assert.Equal(t, auth, "")
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--registry-auth-helper", "missing")
+	assert.ErrorContains(t, err, "unable to get registry credentials: docker-credential-missing get failed")
+}
+
+func TestSearchRegistryColumns(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "table", "--columns", "id,name,Authors", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `ID   NAME         AUTHORS
+2    cloudtrail   The Falco Authors
+     json         The Falco Authors
+`)
+
+	out, err = runSearchRegistry(t, "-r", s.URL, "-o", "table", "--columns", "all", "dummy")
+	assert.NilError(t, err)
+	header := strings.Fields(strings.SplitN(out, "\n", 2)[0])
+	assert.DeepEqual(t, header, []string{"NAME", "DESCRIPTION", "TYPE", "SOURCES", "LICENSE", "ID", "AUTHORS", "CONTACT", "URL", "RESERVED"})
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "-o", "table", "--columns", "name,digest", "--all")
+	assert.Error(t, err, `unknown column "digest", available columns: name, description, type, sources, license, id, authors, contact, url, reserved`)
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "--columns", "name", "--all")
+	assert.Error(t, err, "--columns requires --output table, compact-table or markdown")
+}
+
+func TestSearchRegistryOutputEnv(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "env", "--fuzzy", "cloudtral")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out, "FALCOCTL_PLUGIN_NAME='cloudtrail'\n"), out)
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "-o", "env", "--all")
+	assert.Error(t, err, "env output requires exactly one matching entry, found 4")
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "-o", "env", "--group-by-registry", "dummy")
+	assert.Error(t, err, "--group-by-registry cannot be used with --output env")
+}
+
+func TestSearchRegistryDumpRegistry(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+	reg, err := ioutil.ReadFile(testRegistryFixture)
+	assert.NilError(t, err)
+
+	dir, err := ioutil.TempDir("", "falcoctl-dump")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "registry.yaml")
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--dump-registry", file)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+	dumped, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dumped, reg)
+
+	out, err = runSearchRegistry(t, "-r", s.URL, "--count-only", "--all", "--dump-registry", "-")
+	assert.NilError(t, err)
+	assert.Equal(t, out, string(reg)+"4\n")
+	// the documents of several registries make a YAML stream
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plugins:\n  source:\n    - name: other"))
+	}))
+	defer other.Close()
+	out, err = runSearchRegistry(t, "--registries", s.URL+","+other.URL, "--count-only", "--all", "--dump-registry", file)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "5\n")
+	dumped, err = ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Equal(t, string(dumped), string(reg)+"---\nplugins:\n  source:\n    - name: other\n")
+	decoder := yaml.NewDecoder(bytes.NewReader(dumped))
+	documents := 0
+	for {
+		r := registry.Registry{}
+		err := decoder.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+		documents++
+	}
+	assert.Equal(t, documents, 2)
+}
+
+func TestSearchRegistryRegion(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+	us := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plugins:\n  source:\n    - id: 999\n      source: okta\n      name: okta\n"))
+	}))
+	defer us.Close()
+
+	dir, err := ioutil.TempDir("", "falcoctl-config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	config := filepath.Join(dir, "config.yaml")
+	assert.NilError(t, ioutil.WriteFile(config, []byte("registry-regions:\n  EU: "+s.URL+"\n  us: "+us.URL+"\n"), 0644))
+
+	out, err := runSearchRegistry(t, "-c", config, "--count-only", "--all", "--registry-region", "eu")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "4\n")
+
+	out, err = runSearchRegistry(t, "-c", config, "--count-only", "--all", "--registry-region", "US")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "1\n")
+
+	_, err = runSearchRegistry(t, "-c", config, "--count-only", "--all", "--registry-region", "ap")
+	assert.Error(t, err, `unknown registry region "ap", configured regions: eu, us`)
+
+	_, err = runSearchRegistry(t, "--count-only", "--all", "--registry-region", "ap")
+	assert.Error(t, err, `unknown registry region "ap", no regions configured with --registry-regions`)
+
+	// ENV mappings are region=url pairs, as on the command line
+	os.Setenv("FALCOCTL_REGISTRY_REGIONS", "eu="+s.URL+",us="+us.URL)
+	defer os.Unsetenv("FALCOCTL_REGISTRY_REGIONS")
+	out, err = runSearchRegistry(t, "--count-only", "--all", "--registry-region", "us")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "1\n")
+}
+
+func TestSearchRegistryOutputMarkdown(t *testing.T) {
+	s := newTestRegistryServer(t)
+	defer s.Close()
+
+	out, err := runSearchRegistry(t, "-r", s.URL, "-o", "markdown", "--columns", "name,license", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `| NAME | LICENSE |
+| --- | --- |
+| cloudtrail | Apache-2.0 |
+| json | Apache-2.0 |
+`)
+
+	out, err = runSearchRegistry(t, "-r", s.URL, "-o", "markdown", "--columns", "name", "--no-headers", "json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "|  |\n| --- |\n| cloudtrail |\n| json |\n")
+
+	_, err = runSearchRegistry(t, "-r", s.URL, "--no-headers", "json")
+	assert.Error(t, err, "--no-headers requires --output table, compact-table or markdown")
+}
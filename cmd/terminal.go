@@ -0,0 +1,29 @@
+// +build !linux
+
+/*
+Copyright © 2019 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+)
+
+// terminalWidth returns the width of the terminal out writes to, or 0 when it is not a terminal
+func terminalWidth(out io.Writer) int {
+	// todo > detect the terminal width on other platforms
+	return 0
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2019 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Prefix of the credential helper programs, as for Docker
+const Prefix = "docker-credential-"
+
+// TokenUsername is the username of the credentials whose secret is an identity token
+const TokenUsername = "<token>"
+
+// errNotFoundMessage is the message the helpers print when they store no credentials for a server
+const errNotFoundMessage = "credentials not found in native keychain"
+
+// ErrNotFound is returned when the helper stores no credentials for a server
+var ErrNotFound = errors.New(errNotFoundMessage)
+
+// Credentials are the credentials a helper stores for a server
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Helper runs a Docker credential helper program, following its get/store/erase protocol
+type Helper struct {
+	name string
+}
+
+// New creates a Helper running the docker-credential-<name> program
+func New(name string) *Helper {
+	return &Helper{name: name}
+}
+
+// Program returns the name of the program h runs
+func (h *Helper) Program() string {
+	return Prefix + h.name
+}
+
+// Get returns the credentials stored for serverURL, or ErrNotFound
+func (h *Helper) Get(serverURL string) (*Credentials, error) {
+	out, err := h.run("get", strings.NewReader(serverURL))
+	if err != nil {
+		return nil, err
+	}
+	creds := &Credentials{}
+	if err := json.Unmarshal(out, creds); err != nil {
+		// do not echo out, it holds the secret
+		return nil, fmt.Errorf("invalid credentials from %s for \"%s\"", h.Program(), serverURL)
+	}
+	return creds, nil
+}
+
+// Store stores creds
+func (h *Helper) Store(creds *Credentials) error {
+	in, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", bytes.NewReader(in))
+	return err
+}
+
+// Erase removes the credentials stored for serverURL
+func (h *Helper) Erase(serverURL string) error {
+	_, err := h.run("erase", strings.NewReader(serverURL))
+	return err
+}
+
+// run runs the given protocol action feeding in to the helper program, returning its output
+func (h *Helper) run(action string, in io.Reader) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(h.Program(), action)
+	cmd.Stdin = in
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		// helpers print their errors on stdout
+		msg := strings.TrimSpace(stdout.String())
+		if msg == errNotFoundMessage {
+			return nil, ErrNotFound
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s %s failed: %s", h.Program(), action, msg)
+	}
+	return stdout.Bytes(), nil
+}
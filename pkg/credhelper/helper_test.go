@@ -0,0 +1,63 @@
+package credhelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// fakeHelper is a credential helper storing the credentials of a single server in a file next to it
+const fakeHelper = `#!/bin/sh
+dir=$(dirname "$0")
+case "$1" in
+get)
+	read server
+	if [ -f "$dir/$server" ]; then cat "$dir/$server"; else echo "credentials not found in native keychain"; exit 1; fi ;;
+store)
+	cat > "$dir/stored" && server=$(sed 's/.*"ServerURL":"\([^"]*\)".*/\1/' "$dir/stored") && mv "$dir/stored" "$dir/$server" ;;
+erase)
+	read server
+	rm "$dir/$server" ;;
+*)
+	echo "unknown action $1"; exit 1 ;;
+esac
+`
+
+// withFakeHelper installs fakeHelper as docker-credential-fake in PATH
+func withFakeHelper(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "falcoctl-credhelper")
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, Prefix+"fake"), []byte(fakeHelper), 0755))
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHelper(t *testing.T) {
+	defer withFakeHelper(t)()
+	h := New("fake")
+
+	_, err := h.Get("registry.example.com")
+	assert.Equal(t, err, ErrNotFound)
+
+	creds := &Credentials{ServerURL: "registry.example.com", Username: "falco", Secret: "s3cr3t"}
+	assert.NilError(t, h.Store(creds))
+	got, err := h.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, creds)
+
+	assert.NilError(t, h.Erase("registry.example.com"))
+	_, err = h.Get("registry.example.com")
+	assert.Equal(t, err, ErrNotFound)
+}
+
+func TestHelperMissing(t *testing.T) {
+	_, err := New("missing").Get("registry.example.com")
+	assert.ErrorContains(t, err, "docker-credential-missing get failed")
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AggregateFields are the fields plugins can be aggregated by
+var AggregateFields = []string{"type", "authors", "license"}
+
+// Group represents the plugins sharing the same value of an aggregation field
+type Group struct {
+	Count   int     `yaml:"count"`
+	Plugins Plugins `yaml:"plugins"`
+}
+
+// Groups maps each value of an aggregation field to its group
+type Groups map[string]*Group
+
+// ValidateAggregateField returns an error if field is not one of AggregateFields
+func ValidateAggregateField(field string) error {
+	for _, f := range AggregateFields {
+		if f == field {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown aggregation field \"%s\", must be one of: %s", field, strings.Join(AggregateFields, ", "))
+}
+
+// AggregateBy groups the plugins by the value of the given field
+func (p *Plugins) AggregateBy(field string) (Groups, error) {
+	if err := ValidateAggregateField(field); err != nil {
+		return nil, err
+	}
+	groups := Groups{}
+	group := func(key string) *Group {
+		g, ok := groups[key]
+		if !ok {
+			g = &Group{}
+			groups[key] = g
+		}
+		g.Count++
+		return g
+	}
+	for _, source := range p.Source {
+		var key string
+		switch field {
+		case "type":
+			key = "source"
+		case "authors":
+			key = source.Authors
+		case "license":
+			key = source.License
+		}
+		g := group(key)
+		g.Plugins.Source = append(g.Plugins.Source, source)
+	}
+	for _, extractor := range p.Extractor {
+		var key string
+		switch field {
+		case "type":
+			key = "extractor"
+		case "authors":
+			key = extractor.Authors
+		case "license":
+			key = extractor.License
+		}
+		g := group(key)
+		g.Plugins.Extractor = append(g.Plugins.Extractor, extractor)
+	}
+	return groups, nil
+}
+
+// ToString returns the YAML representation of the groups
+func (g Groups) ToString() (string, error) {
+	return toString(g)
+}
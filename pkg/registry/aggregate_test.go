@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func loadTestRegistry(t *testing.T) *Registry {
+	f, err := os.Open("testdata/registry.yaml")
+	if err != nil {
+		t.Fatalf("registry fixture not found: %v", err)
+	}
+	var r io.ReadCloser = f
+	defer r.Close()
+	reg, err := LoadRegistry(&r)
+	if err != nil {
+		t.Fatalf("error loading registry fixture: %v", err)
+	}
+	return reg
+}
+
+func TestAggregateBy(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	groups, err := reg.Plugins.AggregateBy("type")
+	assert.NilError(t, err)
+	assert.Equal(t, len(groups), 2)
+	assert.Equal(t, groups["source"].Count, 3)
+	assert.Equal(t, len(groups["source"].Plugins.Source), 3)
+	assert.Equal(t, len(groups["source"].Plugins.Extractor), 0)
+	assert.Equal(t, groups["extractor"].Count, 1)
+	assert.Equal(t, groups["extractor"].Plugins.Extractor[0].Name, "json")
+
+	groups, err = reg.Plugins.AggregateBy("license")
+	assert.NilError(t, err)
+	assert.Equal(t, len(groups), 2)
+	assert.Equal(t, groups["Apache-2.0"].Count, 3)
+	assert.Equal(t, groups["MIT"].Count, 1)
+	assert.Equal(t, groups["MIT"].Plugins.Source[0].Name, "dummy")
+}
+
+func TestAggregateByUnknownField(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	_, err := reg.Plugins.AggregateBy("color")
+	assert.Error(t, err, `unknown aggregation field "color", must be one of: type, authors, license`)
+}
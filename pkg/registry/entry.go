@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"sort"
+)
+
+// Entry is a flattened view of either a source or an extractor plugin
+type Entry struct {
+	Type        string
+	ID          uint
+	Name        string
+	Sources     []string
+	Description string
+	Authors     string
+	Contact     string
+	URL         string
+	License     string
+	Reserved    bool
+	Score       float64
+}
+
+// Entries returns the plugins as a list of entries, by descending score, sources first on equal scores
+func (p *Plugins) Entries() []Entry {
+	entries := make([]Entry, 0, p.Count())
+	for _, s := range p.Source {
+		entries = append(entries, Entry{
+			Type:        "source",
+			ID:          s.ID,
+			Name:        s.Name,
+			Sources:     []string{s.Source},
+			Description: s.Description,
+			Authors:     s.Authors,
+			Contact:     s.Contact,
+			URL:         s.URL,
+			License:     s.License,
+			Reserved:    s.Reserved,
+			Score:       s.Score,
+		})
+	}
+	for _, e := range p.Extractor {
+		entries = append(entries, Entry{
+			Type:        "extractor",
+			Name:        e.Name,
+			Sources:     e.Sources,
+			Description: e.Description,
+			Authors:     e.Authors,
+			Contact:     e.Contact,
+			URL:         e.URL,
+			License:     e.License,
+			Reserved:    e.Reserved,
+			Score:       e.Score,
+		})
+	}
+	// only fuzzy matches have a score, the others keep their order
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Score > entries[j].Score
+	})
+	return entries
+}
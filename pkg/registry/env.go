@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvPrefix prefixes the names of the variables plugins are rendered to
+const EnvPrefix = "FALCOCTL_PLUGIN_"
+
+// ToEnv renders the only plugin as KEY='value' lines, to be evaluated by a shell
+func (p *Plugins) ToEnv() (string, error) {
+	entries := p.Entries()
+	if len(entries) != 1 {
+		return "", fmt.Errorf("env output requires exactly one matching entry, found %d", len(entries))
+	}
+	e := entries[0]
+	vars := [][2]string{
+		{"NAME", e.Name},
+		{"TYPE", e.Type},
+	}
+	if e.Type == "source" {
+		vars = append(vars, [2]string{"ID", fmt.Sprint(e.ID)})
+	}
+	vars = append(vars,
+		[2]string{"SOURCES", strings.Join(e.Sources, ",")},
+		[2]string{"URL", e.URL},
+		[2]string{"LICENSE", e.License},
+	)
+
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "%s%s=%s\n", EnvPrefix, v[0], shellQuote(v[1]))
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// shellQuote quotes s to be read back literally by a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,29 @@
+package registry
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestToEnv(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	out, err := reg.SearchByKeywords([]string{"dummy"}).ToEnv()
+	assert.NilError(t, err)
+	assert.Equal(t, out, `FALCOCTL_PLUGIN_NAME='dummy'
+FALCOCTL_PLUGIN_TYPE='source'
+FALCOCTL_PLUGIN_ID='3'
+FALCOCTL_PLUGIN_SOURCES='dummy'
+FALCOCTL_PLUGIN_URL='https://github.com/falcosecurity/plugins/tree/master/plugins/dummy'
+FALCOCTL_PLUGIN_LICENSE='MIT'`)
+
+	_, err = reg.Plugins.ToEnv()
+	assert.Error(t, err, "env output requires exactly one matching entry, found 4")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, shellQuote(""), `''`)
+	assert.Equal(t, shellQuote("a b"), `'a b'`)
+	assert.Equal(t, shellQuote("it's $HOME"), `'it'\''s $HOME'`)
+}
@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultMinScore is the default minimum score for an entry to be a fuzzy match
+const DefaultMinScore = 0.6
+
+// SearchFuzzy returns the plugins whose name or description fuzzily match any of the keywords
+// with a score of at least minScore, ordered by score (best first).
+//
+// Sources and extractors are ranked on their own, while Entries ranks them together by their Score.
+func (r *Registry) SearchFuzzy(keywords []string, minScore float64) *Plugins {
+	plugins := &Plugins{}
+
+	matches := []match{}
+	for i, source := range r.Plugins.Source {
+		matches = append(matches, match{i, matchScore(keywords, source.Name, source.Description)})
+	}
+	for _, m := range rank(matches, minScore) {
+		source := r.Plugins.Source[m.index]
+		source.Score = m.score
+		plugins.Source = append(plugins.Source, source)
+	}
+
+	matches = []match{}
+	for i, extractor := range r.Plugins.Extractor {
+		matches = append(matches, match{i, matchScore(keywords, extractor.Name, extractor.Description)})
+	}
+	for _, m := range rank(matches, minScore) {
+		extractor := r.Plugins.Extractor[m.index]
+		extractor.Score = m.score
+		plugins.Extractor = append(plugins.Extractor, extractor)
+	}
+
+	return plugins
+}
+
+type match struct {
+	index int
+	score float64
+}
+
+// rank drops the matches scoring less than minScore and orders the others by descending score
+func rank(matches []match, minScore float64) []match {
+	ranked := []match{}
+	for _, m := range matches {
+		if m.score >= minScore {
+			ranked = append(ranked, m)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// matchScore returns the best similarity, between 0 and 1, of any keyword against the name or the description words
+func matchScore(keywords []string, name, description string) float64 {
+	best := 0.0
+	words := append([]string{name}, strings.FieldsFunc(description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})...)
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		for _, word := range words {
+			if s := similarity(keyword, strings.ToLower(word)); s > best {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// similarity returns 1 when word contains keyword, otherwise their normalized Levenshtein similarity
+func similarity(keyword, word string) float64 {
+	if keyword == "" {
+		return 0
+	}
+	if strings.Contains(word, keyword) {
+		return 1
+	}
+	a, b := []rune(keyword), []rune(word)
+	max := len(a)
+	if len(b) > max {
+		max = len(b)
+	}
+	return 1 - float64(levenshtein(a, b))/float64(max)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
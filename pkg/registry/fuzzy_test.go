@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSearchFuzzy(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	// dummy (1 edit away) must rank before cloudtrail (3 edits away), despite the registry order
+	plugins := reg.SearchFuzzy([]string{"dumy", "clodtrial"}, 0.5)
+	assert.Equal(t, len(plugins.Source), 2)
+	assert.Equal(t, plugins.Source[0].Name, "dummy")
+	assert.Equal(t, plugins.Source[1].Name, "cloudtrail")
+	assert.Equal(t, len(plugins.Extractor), 0)
+
+	// description words are matched too, and exact matches rank first
+	plugins = reg.SearchFuzzy([]string{"jsn", "kubernetes"}, 0.5)
+	assert.Equal(t, len(plugins.Source), 2)
+	assert.Equal(t, plugins.Source[0].Name, "k8saudit")
+	assert.Equal(t, plugins.Source[1].Name, "cloudtrail")
+	assert.Equal(t, len(plugins.Extractor), 1)
+	assert.Equal(t, plugins.Extractor[0].Name, "json")
+}
+
+func TestSearchFuzzyMinScore(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	plugins := reg.SearchFuzzy([]string{"dumy", "clodtrial"}, 0.75)
+	assert.Equal(t, len(plugins.Source), 1)
+	assert.Equal(t, plugins.Source[0].Name, "dummy")
+
+	plugins = reg.SearchFuzzy([]string{"dumy", "clodtrial"}, 0.9)
+	assert.Equal(t, plugins.Count(), 0)
+}
+
+func TestSearchFuzzyEntries(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	// the json extractor matches exactly, so it ranks before the dummy source (1 edit away)
+	entries := reg.SearchFuzzy([]string{"extract", "dumy"}, 0.75).Entries()
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Name, "json")
+	assert.Equal(t, entries[0].Type, "extractor")
+	assert.Equal(t, entries[0].Score, 1.0)
+	assert.Equal(t, entries[1].Name, "dummy")
+	assert.Equal(t, entries[1].Type, "source")
+
+	// without a score, sources come first
+	entries = reg.SearchByKeywords([]string{"json"}).Entries()
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Name, "cloudtrail")
+	assert.Equal(t, entries[1].Name, "json")
+}
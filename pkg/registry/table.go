@@ -0,0 +1,246 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Column is a column of the table representation of plugins
+type Column struct {
+	Name  string
+	value func(e Entry) string
+}
+
+// Columns are all the available table columns, in order of importance.
+//
+// Less important columns are the first ones to be elided when the table does not fit.
+var Columns = []Column{
+	{"NAME", func(e Entry) string { return e.Name }},
+	{"DESCRIPTION", func(e Entry) string { return e.Description }},
+	{"TYPE", func(e Entry) string { return e.Type }},
+	{"SOURCES", func(e Entry) string { return strings.Join(e.Sources, ",") }},
+	{"LICENSE", func(e Entry) string { return e.License }},
+	{"ID", func(e Entry) string {
+		if e.Type != "source" {
+			return ""
+		}
+		return fmt.Sprint(e.ID)
+	}},
+	{"AUTHORS", func(e Entry) string { return e.Authors }},
+	{"CONTACT", func(e Entry) string { return e.Contact }},
+	{"URL", func(e Entry) string { return e.URL }},
+	{"RESERVED", func(e Entry) string { return fmt.Sprint(e.Reserved) }},
+}
+
+// DefaultColumns are the columns shown by default
+var DefaultColumns = []string{"NAME", "TYPE", "SOURCES", "LICENSE", "DESCRIPTION"}
+
+// minColumnWidth is the width below which columns are not truncated
+const minColumnWidth = 8
+
+// TableOptions controls how plugins are rendered as a table
+type TableOptions struct {
+	// Columns to show, in order
+	Columns []string
+	// Padding between columns
+	Padding int
+	// Width the table must fit in, eliding and truncating columns as needed (0 means unlimited)
+	Width int
+	// NoHeaders omits the header row
+	NoHeaders bool
+}
+
+// ToTable renders the plugins as a table
+func (p *Plugins) ToTable(opts TableOptions) (string, error) {
+	cols, rows, err := p.tableRows(opts)
+	if err != nil {
+		return "", err
+	}
+
+	widths := make([]int, len(cols))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	visible := make([]bool, len(cols))
+	for i := range visible {
+		visible[i] = true
+	}
+	if opts.Width > 0 {
+		fit(cols, widths, visible, opts)
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		line := []string{}
+		for i, cell := range row {
+			if !visible[i] {
+				continue
+			}
+			line = append(line, pad(truncate(cell, widths[i]), widths[i]))
+		}
+		b.WriteString(strings.TrimRight(strings.Join(line, strings.Repeat(" ", opts.Padding)), " "))
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// ToMarkdown renders the plugins as a GitHub flavored Markdown table, ignoring opts.Padding and opts.Width
+func (p *Plugins) ToMarkdown(opts TableOptions) (string, error) {
+	cols, rows, err := p.tableRows(opts)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.NoHeaders {
+		// a table needs a header row, leave its cells empty
+		rows = append([][]string{make([]string, len(cols))}, rows...)
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = markdownEscaper.Replace(cell)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.TrimSuffix(strings.Repeat("| --- ", len(cols)), " ") + " |\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// markdownEscaper escapes the characters that would break a Markdown table cell
+var markdownEscaper = strings.NewReplacer(`|`, `\|`, "\r\n", " ", "\n", " ")
+
+// tableRows returns the columns of opts and the cells of the plugins in them, headers first unless omitted
+func (p *Plugins) tableRows(opts TableOptions) ([]Column, [][]string, error) {
+	cols := []Column{}
+	for _, name := range opts.Columns {
+		col, err := lookupColumn(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		cols = append(cols, col)
+	}
+
+	rows := [][]string{}
+	if !opts.NoHeaders {
+		header := []string{}
+		for _, col := range cols {
+			header = append(header, col.Name)
+		}
+		rows = append(rows, header)
+	}
+	for _, e := range p.Entries() {
+		row := []string{}
+		for _, col := range cols {
+			row = append(row, col.value(e))
+		}
+		rows = append(rows, row)
+	}
+	return cols, rows, nil
+}
+
+// fit elides the least important columns, until the table can fit opts.Width,
+// and then truncates its widest column so it does.
+func fit(cols []Column, widths []int, visible []bool, opts TableOptions) {
+	widest := func() int {
+		widest := -1
+		for i, w := range widths {
+			if visible[i] && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		return widest
+	}
+	total := func() int {
+		t, n := 0, 0
+		for i, w := range widths {
+			if visible[i] {
+				t += w
+				n++
+			}
+		}
+		return t + (n-1)*opts.Padding
+	}
+	// the table width when its widest column is truncated as much as possible
+	truncated := func() int {
+		if w := widths[widest()]; w > minColumnWidth {
+			return total() - w + minColumnWidth
+		}
+		return total()
+	}
+	importance := func(i int) int {
+		for rank, col := range Columns {
+			if col.Name == cols[i].Name {
+				return rank
+			}
+		}
+		return len(Columns)
+	}
+
+	// always keep the two most important of the requested columns
+	for shown := len(cols); truncated() > opts.Width && shown > 2; shown-- {
+		least := -1
+		for i := range cols {
+			if visible[i] && (least < 0 || importance(i) > importance(least)) {
+				least = i
+			}
+		}
+		visible[least] = false
+	}
+
+	if excess := total() - opts.Width; excess > 0 {
+		i := widest()
+		widths[i] -= excess
+		if widths[i] < minColumnWidth {
+			widths[i] = minColumnWidth
+		}
+	}
+}
+
+// ColumnNames returns the names of all the available columns, in order of importance
+func ColumnNames() []string {
+	names := make([]string, 0, len(Columns))
+	for _, col := range Columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// ValidateColumn returns an error listing the available columns if name is not one of them
+func ValidateColumn(name string) error {
+	_, err := lookupColumn(name)
+	return err
+}
+
+func lookupColumn(name string) (Column, error) {
+	for _, col := range Columns {
+		if col.Name == strings.ToUpper(name) {
+			return col, nil
+		}
+	}
+	names := ColumnNames()
+	for i := range names {
+		names[i] = strings.ToLower(names[i])
+	}
+	return Column{}, fmt.Errorf("unknown column \"%s\", available columns: %s", name, strings.Join(names, ", "))
+}
+
+// truncate shortens s to width runes, ending it with an ellipsis when truncated
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestToTable(t *testing.T) {
+	reg := loadTestRegistry(t)
+	plugins := reg.SearchByKeywords([]string{"json"})
+
+	out, err := plugins.ToTable(TableOptions{Columns: DefaultColumns, Padding: 3})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `NAME         TYPE        SOURCES                    LICENSE      DESCRIPTION
+cloudtrail   source      aws_cloudtrail             Apache-2.0   Reads Cloudtrail JSON logs from files/S3 and injects as events
+json         extractor   aws_cloudtrail,k8s_audit   Apache-2.0   Extract values from any JSON payload`)
+}
+
+func TestToTableCompact(t *testing.T) {
+	reg := loadTestRegistry(t)
+	plugins := reg.SearchByKeywords([]string{"json"})
+
+	// fits: just less padding
+	out, err := plugins.ToTable(TableOptions{Columns: DefaultColumns, Padding: 1, Width: 120})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `NAME       TYPE      SOURCES                  LICENSE    DESCRIPTION
+cloudtrail source    aws_cloudtrail           Apache-2.0 Reads Cloudtrail JSON logs from files/S3 and injects as events
+json       extractor aws_cloudtrail,k8s_audit Apache-2.0 Extract values from any JSON payload`)
+
+	// the less important columns are elided first, then the description is truncated
+	out, err = plugins.ToTable(TableOptions{Columns: DefaultColumns, Padding: 1, Width: 50})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `NAME       TYPE      DESCRIPTION
+cloudtrail source    Reads Cloudtrail JSON logs f…
+json       extractor Extract values from any JSON…`)
+
+	// never below the two most important columns
+	out, err = plugins.ToTable(TableOptions{Columns: DefaultColumns, Padding: 1, Width: 20})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `NAME       DESCRIPT…
+cloudtrail Reads Cl…
+json       Extract …`)
+}
+
+func TestToTableNoHeaders(t *testing.T) {
+	reg := loadTestRegistry(t)
+	plugins := reg.SearchByKeywords([]string{"json"})
+
+	out, err := plugins.ToTable(TableOptions{Columns: []string{"name", "type"}, Padding: 1, NoHeaders: true})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `cloudtrail source
+json       extractor`)
+}
+
+func TestToMarkdown(t *testing.T) {
+	plugins := &Plugins{
+		Source:    []Source{{ID: 1, Source: "pipes", Name: "pipes", Description: "Reads a | b\nand c"}},
+		Extractor: []Extractor{{Sources: []string{"pipes", "json"}, Name: "json", Description: "Extract values"}},
+	}
+
+	out, err := plugins.ToMarkdown(TableOptions{Columns: []string{"name", "sources", "description"}})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `| NAME | SOURCES | DESCRIPTION |
+| --- | --- | --- |
+| pipes | pipes | Reads a \| b and c |
+| json | pipes,json | Extract values |`)
+
+	out, err = plugins.ToMarkdown(TableOptions{Columns: []string{"name", "id"}, NoHeaders: true})
+	assert.NilError(t, err)
+	assert.Equal(t, out, `|  |  |
+| --- | --- |
+| pipes | 1 |
+| json |  |`)
+
+	_, err = plugins.ToMarkdown(TableOptions{Columns: []string{"digest"}})
+	assert.ErrorContains(t, err, `unknown column "digest"`)
+}
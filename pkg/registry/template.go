@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Templates are the built-in templates plugins can be rendered with, executed once per entry
+var Templates = map[string]string{
+	"short": `{{.Name}} ({{.Type}}): {{.Description}}
+`,
+	"detailed": `Name:        {{.Name}}
+Type:        {{.Type}}
+{{- if eq .Type "source"}}
+ID:          {{.ID}}{{end}}
+Sources:     {{join .Sources ", "}}
+Description: {{.Description}}
+Authors:     {{.Authors}}
+Contact:     {{.Contact}}
+URL:         {{.URL}}
+License:     {{.License}}
+Reserved:    {{.Reserved}}
+
+`,
+	"url": `{{.Name}} {{.URL}}
+`,
+}
+
+// TemplateNames returns the sorted names of the built-in templates
+func TemplateNames() []string {
+	names := make([]string, 0, len(Templates))
+	for name := range Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateTemplate returns an error listing the available templates if name is not one of them
+func ValidateTemplate(name string) error {
+	if _, ok := Templates[name]; !ok {
+		return fmt.Errorf("unknown template \"%s\", available templates: %s", name, strings.Join(TemplateNames(), ", "))
+	}
+	return nil
+}
+
+// ToTemplate renders each plugin with the named built-in template
+func (p *Plugins) ToTemplate(name string) (string, error) {
+	if err := ValidateTemplate(name); err != nil {
+		return "", err
+	}
+	t, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Parse(Templates[name])
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	for _, e := range p.Entries() {
+		if err := t.Execute(buf, e); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
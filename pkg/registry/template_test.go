@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestToTemplate(t *testing.T) {
+	reg := loadTestRegistry(t)
+	plugins := reg.SearchByKeywords([]string{"cloudtrail"})
+
+	out, err := plugins.ToTemplate("short")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `cloudtrail (source): Reads Cloudtrail JSON logs from files/S3 and injects as events`)
+
+	out, err = plugins.ToTemplate("url")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `cloudtrail https://github.com/falcosecurity/plugins/tree/master/plugins/cloudtrail`)
+
+	out, err = reg.SearchByKeywords([]string{"json", "dummy"}).ToTemplate("detailed")
+	assert.NilError(t, err)
+	assert.Equal(t, out, `Name:        cloudtrail
+Type:        source
+ID:          2
+Sources:     aws_cloudtrail
+Description: Reads Cloudtrail JSON logs from files/S3 and injects as events
+Authors:     The Falco Authors
+Contact:     https://falco.org/community
+URL:         https://github.com/falcosecurity/plugins/tree/master/plugins/cloudtrail
+License:     Apache-2.0
+Reserved:    false
+
+Name:        dummy
+Type:        source
+ID:          3
+Sources:     dummy
+Description: Reference plugin used to document interface
+Authors:     Sample Plugin Authors
+Contact:     https://github.com/falcosecurity/plugins
+URL:         https://github.com/falcosecurity/plugins/tree/master/plugins/dummy
+License:     MIT
+Reserved:    false
+
+Name:        json
+Type:        extractor
+Sources:     aws_cloudtrail, k8s_audit
+Description: Extract values from any JSON payload
+Authors:     The Falco Authors
+Contact:     https://falco.org/community
+URL:         https://github.com/falcosecurity/plugins/tree/master/plugins/json
+License:     Apache-2.0
+Reserved:    false
+`)
+}
+
+func TestToTemplateUnknown(t *testing.T) {
+	reg := loadTestRegistry(t)
+
+	_, err := reg.Plugins.ToTemplate("oci-ref")
+	assert.Error(t, err, `unknown template "oci-ref", available templates: detailed, short, url`)
+}
@@ -17,6 +17,8 @@ type Source struct {
 	URL         string `yaml:"url"`
 	License     string `yaml:"license"`
 	Reserved    bool   `yaml:"reserved"`
+	// Score is how well the plugin matched the keywords of SearchFuzzy
+	Score float64 `yaml:"-"`
 }
 
 type Extractor struct {
@@ -28,6 +30,8 @@ type Extractor struct {
 	URL         string   `yaml:"url"`
 	License     string   `yaml:"license"`
 	Reserved    bool     `yaml:"reserved"`
+	// Score is how well the plugin matched the keywords of SearchFuzzy
+	Score float64 `yaml:"-"`
 }
 
 type Plugins struct {
@@ -35,8 +39,16 @@ type Plugins struct {
 	Extractor []Extractor `yaml:"extractor"`
 }
 
+func (p *Plugins) Count() int {
+	return len(p.Source) + len(p.Extractor)
+}
+
 func (p *Plugins) ToString() (string, error) {
-	bytes, err := yaml.Marshal(p)
+	return toString(p)
+}
+
+func toString(in interface{}) (string, error) {
+	bytes, err := yaml.Marshal(in)
 	if err != nil {
 		return "", err
 	}
@@ -52,25 +64,31 @@ func (r *Registry) SearchByKeywords(keywords []string) *Plugins {
 	plugins := &Plugins{}
 	for _, source := range r.Plugins.Source {
 		for _, keyword := range keywords {
-			if strings.Contains(strings.ToLower(source.Description), strings.ToLower(keyword)) {
-				plugins.Source = append(plugins.Source, source)
-			} else if strings.Contains(strings.ToLower(source.Name), strings.ToLower(keyword)) {
+			if strings.Contains(strings.ToLower(source.Description), strings.ToLower(keyword)) ||
+				strings.Contains(strings.ToLower(source.Name), strings.ToLower(keyword)) {
 				plugins.Source = append(plugins.Source, source)
+				break
 			}
 		}
 	}
 	for _, extractor := range r.Plugins.Extractor {
 		for _, keyword := range keywords {
-			if strings.Contains(strings.ToLower(extractor.Description), strings.ToLower(keyword)) {
-				plugins.Extractor = append(plugins.Extractor, extractor)
-			} else if strings.Contains(strings.ToLower(extractor.Name), strings.ToLower(keyword)) {
+			if strings.Contains(strings.ToLower(extractor.Description), strings.ToLower(keyword)) ||
+				strings.Contains(strings.ToLower(extractor.Name), strings.ToLower(keyword)) {
 				plugins.Extractor = append(plugins.Extractor, extractor)
+				break
 			}
 		}
 	}
 	return plugins
 }
 
+func (r *Registry) Merge(other *Registry) {
+	r.Plugins.Source = append(r.Plugins.Source, other.Plugins.Source...)
+	r.Plugins.Extractor = append(r.Plugins.Extractor, other.Plugins.Extractor...)
+	r.ReservedSources = append(r.ReservedSources, other.ReservedSources...)
+}
+
 func LoadRegistry(r *io.ReadCloser) (*Registry, error) {
 	decoder := yaml.NewDecoder(*r)
 	registry := &Registry{}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -204,7 +205,7 @@ func (g *GRPCTLS) Generate() error {
 func (g *GRPCTLS) FlushToDisk(path string) error {
 	p, err := satisfyDir(path)
 	if err != nil {
-		return fmt.Errorf("invalid path: %v", err)
+		return fmt.Errorf("invalid path: %w", err)
 	}
 	path = p
 
@@ -212,12 +213,28 @@ func (g *GRPCTLS) FlushToDisk(path string) error {
 		f := filepath.Join(path, name)
 		logger.Infof("Writing: %s", f)
 		if err := ioutil.WriteFile(f, g.certs[name].Bytes(), 0600); err != nil {
-			return fmt.Errorf(`unable to write "%s": %v`, name, err)
+			return fmt.Errorf(`unable to write "%s": %w`, name, err)
 		}
 	}
 	return nil
 }
 
+// Verify is used to check that the cert material on disk at the given path matches the one from a GRPCTLS.
+func (g *GRPCTLS) Verify(path string) error {
+	for _, name := range certsFilenames {
+		f := filepath.Join(path, name)
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf(`unable to read back "%s": %w`, name, err)
+		}
+		if sha256.Sum256(b) != sha256.Sum256(g.certs[name].Bytes()) {
+			return fmt.Errorf(`digest mismatch for "%s": the written content differs from the generated one`, f)
+		}
+		logger.Debugf("Verified: %s", f)
+	}
+	return nil
+}
+
 func satisfyDir(dirName string) (string, error) {
 	abs, err := filepath.Abs(dirName)
 	if err != nil {
@@ -227,5 +244,5 @@ func satisfyDir(dirName string) (string, error) {
 	if err == nil || os.IsExist(err) {
 		return abs, nil
 	}
-	return "", fmt.Errorf("unable to ensure dir: %v", err)
+	return "", fmt.Errorf("unable to ensure dir: %w", err)
 }
@@ -0,0 +1,39 @@
+package tls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "falcoctl-tls")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	g := GRPCTLSGenerator("US", "falcosecurity", "localhost", 1)
+	g.RSABits = 1024
+	if err := g.Generate(); err != nil {
+		t.Fatalf("error generating TLS material: %v", err)
+	}
+	if err := g.FlushToDisk(dir); err != nil {
+		t.Fatalf("error writing TLS material: %v", err)
+	}
+	assert.NilError(t, g.Verify(dir))
+
+	corrupted := filepath.Join(dir, ServerCert)
+	if err := ioutil.WriteFile(corrupted, []byte("corrupted"), 0600); err != nil {
+		t.Fatalf("error corrupting TLS material: %v", err)
+	}
+	assert.Error(t, g.Verify(dir), `digest mismatch for "`+corrupted+`": the written content differs from the generated one`)
+
+	if err := os.Remove(corrupted); err != nil {
+		t.Fatalf("error removing TLS material: %v", err)
+	}
+	assert.ErrorContains(t, g.Verify(dir), `unable to read back "`+ServerCert+`"`)
+}